@@ -0,0 +1,168 @@
+package crowdnfo
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+
+	"github.com/crowdnfo/crowdnfo-go/internal"
+	"github.com/crowdnfo/crowdnfo-go/internal/files"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// BatchReport is the aggregated outcome of ProcessReleases.
+type BatchReport struct {
+	Releases   []BatchResult              `json:"releases"`   // one entry per path, in the order given
+	Categories map[string]*CategoryTotals `json:"categories"` // totals per CrowdNFO category
+	Failures   map[string][]string        `json:"failures"`   // failed release paths grouped by error kind, see ErrorKind
+	Succeeded  int                        `json:"succeeded"`
+	Failed     int                        `json:"failed"`
+}
+
+// BatchResult is the outcome of a single release of a batch
+type BatchResult struct {
+	ReleasePath string                `json:"releasePath"`
+	ReleaseName string                `json:"releaseName"`
+	Category    string                `json:"category"`
+	Result      *typing.ProcessResult `json:"result,omitempty"`
+	Err         error                 `json:"-"`
+	Error       string                `json:"error,omitempty"` // Err as text
+	ErrorKind   string                `json:"errorKind,omitempty"`
+}
+
+// CategoryTotals counts the releases and assets of a category
+type CategoryTotals struct {
+	Releases       int `json:"releases"`
+	FailedReleases int `json:"failedReleases"`
+	Uploaded       int `json:"uploaded"`
+	Skipped        int `json:"skipped"`
+	Failed         int `json:"failed"`
+	Queued         int `json:"queued"`
+	Filtered       int `json:"filtered"`
+}
+
+// ProcessReleases processes many releases with the same options, ReleasePath and the per-release
+// overrides (ReleaseName, MediaFilePath, NFOFilePath, FileListRoot, Episodes) are ignored.
+// The API key is resolved and the MediaInfo version checked once up front, the key itself is only
+// validated by the first request. An error is only returned if no API key is available, the client
+// is already known to be unsupported, its proxy or TLS settings are invalid or MediaInfo is too old.
+// Up to opts.BatchConcurrency releases are processed at once and a failing release does not stop
+// the others. Once a response declares the client unsupported, releases not started yet fail with
+// ErrUnsupportedVersion. Once ctx is done, they are reported as failed with ctx.Err().
+func ProcessReleases(ctx context.Context, paths []string, opts Options) (*BatchReport, error) {
+	env, err := newEnvironment(ctx, opts, max(opts.BatchConcurrency, 1))
+	if err != nil {
+		return nil, err
+	}
+	opts.APIKey = env.apiKey
+	opts.ReleaseName, opts.MediaFilePath, opts.NFOFilePath, opts.FileListRoot, opts.Episodes = "", "", "", "", nil
+
+	results := make([]BatchResult, len(paths))
+	workers := internal.NewSemaphore(max(opts.BatchConcurrency, 1))
+	var wg sync.WaitGroup
+
+	for i, path := range paths {
+		releaseName := files.GetBaseOrName(path)
+		if releaseName == "" {
+			releaseName = filepath.Base(path)
+		}
+		results[i] = BatchResult{ReleasePath: path, ReleaseName: releaseName, Category: getCategory(opts.Category, releaseName)}
+
+		if err := workers.Acquire(ctx); err != nil {
+			results[i].Err = err
+			continue
+		}
+		// Nothing would be accepted anymore once the server refused this client
+		if env.client.Unsupported() {
+			workers.Release()
+			results[i].Err = ErrUnsupportedVersion
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer workers.Release()
+
+			releaseOpts := opts
+			releaseOpts.ReleasePath = path
+			job := newJob(env, releaseOpts)
+			results[i].Result, results[i].Err = job.Process(ctx)
+			// Report the name and category actually used, e.g. after normalization
+			if job.ReleaseName != "" {
+				results[i].ReleaseName, results[i].Category = job.ReleaseName, job.Category
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &BatchReport{
+		Releases:   results,
+		Categories: make(map[string]*CategoryTotals),
+		Failures:   make(map[string][]string),
+	}
+	for i := range report.Releases {
+		report.add(&report.Releases[i])
+	}
+	return report, nil
+}
+
+// add counts a release into the totals
+func (r *BatchReport) add(release *BatchResult) {
+	totals := r.Categories[release.Category]
+	if totals == nil {
+		totals = &CategoryTotals{}
+		r.Categories[release.Category] = totals
+	}
+	totals.Releases++
+
+	if release.Err != nil {
+		release.Error = release.Err.Error()
+		release.ErrorKind = ErrorKind(release.Err)
+		r.Failures[release.ErrorKind] = append(r.Failures[release.ErrorKind], release.ReleasePath)
+		r.Failed++
+		totals.FailedReleases++
+	} else {
+		r.Succeeded++
+	}
+
+	if release.Result == nil {
+		return
+	}
+	for _, asset := range release.Result.Assets {
+		switch asset.Status {
+		case typing.AssetUploaded:
+			totals.Uploaded++
+		case typing.AssetSkipped:
+			totals.Skipped++
+		case typing.AssetFailed:
+			totals.Failed++
+		case typing.AssetQueued:
+			totals.Queued++
+		case typing.AssetFiltered:
+			totals.Filtered++
+		}
+	}
+}
+
+// ErrorKind classifies an error for reports: "unauthorized", "rate-limited", "already-exists",
+// "validation", "not-found", "unsupported-version", "canceled" or "other".
+func ErrorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrRateLimited):
+		return "rate-limited"
+	case errors.Is(err, ErrAlreadyExists):
+		return "already-exists"
+	case errors.Is(err, ErrValidation):
+		return "validation"
+	case errors.Is(err, ErrNotFound):
+		return "not-found"
+	case errors.Is(err, ErrUnsupportedVersion):
+		return "unsupported-version"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	}
+	return "other"
+}
@@ -0,0 +1,100 @@
+package crowdnfo_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crowdnfo/crowdnfo-go"
+	"github.com/crowdnfo/crowdnfo-go/crowdnfotest"
+)
+
+func writeRelease(t *testing.T, name string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for file, content := range map[string]string{"release.mkv": name, "release.nfo": "NFO " + name} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestProcessReleases(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	paths := []string{
+		writeRelease(t, "First.Movie.2024.1080p.BluRay.x264-GRP"),
+		filepath.Join(t.TempDir(), "Missing.Movie.2024.1080p.BluRay.x264-GRP"),
+		writeRelease(t, "Second.Movie.2024.2160p.WEB.h265-GRP"),
+		writeRelease(t, "Some.Show.S01E01.1080p.WEB.h264-GRP"),
+	}
+	server.AddRelease("Second.Movie.2024.2160p.WEB.h265-GRP", "Movies", "NFO")
+
+	opts := newTestOptions(t, server, "")
+	opts.SkipExisting = true
+	opts.BatchConcurrency = 2
+
+	report, err := crowdnfo.ProcessReleases(context.Background(), paths, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(report.Releases) != len(paths) {
+		t.Fatalf("Expected %d results, got %d", len(paths), len(report.Releases))
+	}
+	for i, release := range report.Releases {
+		if release.ReleasePath != paths[i] {
+			t.Errorf("Expected results in path order, got %s at %d", release.ReleasePath, i)
+		}
+	}
+	if report.Succeeded != 3 || report.Failed != 1 {
+		t.Errorf("Expected 3 succeeded and 1 failed, got %d and %d", report.Succeeded, report.Failed)
+	}
+	if failed := report.Failures["other"]; len(failed) != 1 || failed[0] != paths[1] {
+		t.Errorf("Expected the missing release to fail, got %v", report.Failures)
+	}
+
+	movies := report.Categories["Movies"]
+	if movies == nil || movies.Releases != 3 || movies.FailedReleases != 1 || movies.Uploaded != 5 || movies.Skipped != 1 {
+		t.Errorf("Unexpected movie totals: %+v", movies)
+	}
+	if tv := report.Categories["TV"]; tv == nil || tv.Releases != 1 || tv.Uploaded != 3 {
+		t.Errorf("Unexpected TV totals: %+v", tv)
+	}
+}
+
+func TestProcessReleasesEnvironmentCheck(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	opts := newTestOptions(t, server, "")
+	opts.APIKey = ""
+	if _, err := crowdnfo.ProcessReleases(context.Background(), []string{t.TempDir()}, opts); err == nil {
+		t.Errorf("Expected an error without API key")
+	}
+	if server.Requests() != 0 {
+		t.Errorf("Expected no requests, got %d", server.Requests())
+	}
+}
+
+func TestProcessReleasesNormalizedName(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	opts := newTestOptions(t, server, "")
+	opts.ReleaseNameMode = crowdnfo.ReleaseNameNormalize
+
+	report, err := crowdnfo.ProcessReleases(context.Background(), []string{writeRelease(t, "Some Movie (2024) 1080p BluRay x264 - GRP")}, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name := report.Releases[0].ReleaseName; name != "Some.Movie.2024.1080p.BluRay.x264-GRP" {
+		t.Errorf("Expected the normalized name in the report, got %q", name)
+	}
+}
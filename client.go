@@ -0,0 +1,148 @@
+package crowdnfo
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/api"
+	"github.com/crowdnfo/crowdnfo-go/internal/redact"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// Client holds the configuration used to talk to the CrowdNFO API.
+// The zero value is ready to use and behaves like the built-in default: it talks to
+// https://crowdnfo.net with a 30 second timeout per request.
+// A Client is safe for concurrent use and should be reused so connections are pooled.
+// Its fields must not be modified after the first request has been made.
+type Client struct {
+	APIKey          string                    // optional, used by the read methods, uploads use Options.APIKey
+	Credentials     typing.CredentialProvider // optional, takes precedence over APIKey
+	BaseURL         string                    // optional, defaults to "https://crowdnfo.net"
+	HTTPClient      *http.Client              // optional, takes precedence over Transport
+	Transport       http.RoundTripper         // optional, defaults to http.DefaultTransport
+	UploadTimeout   time.Duration             // optional, timeout per MediaInfo/NFO upload attempt, defaults to 30s
+	FileListTimeout time.Duration             // optional, timeout per file list upload attempt, defaults to 30s
+	QueryTimeout    time.Duration             // optional, timeout per read request attempt, defaults to 30s
+	UserAgentSuffix string                    // optional, appended to the "crowdnfo-go/<version>" User-Agent
+	Retry           typing.RetryPolicy        // optional, defaults to 3 attempts with exponential backoff
+	NoticeCB        typing.NoticeCB           // optional, called when the server announces an update or deprecation
+	QueueDir        string                    // optional, spool uploads failing during an outage here for FlushQueue
+	QueueMaxAge     time.Duration             // optional, queued uploads older than this are dropped, defaults to 7 days
+	RateLimit       float64                   // optional, requests per second shared by all requests of this client, 0 for no limit
+	RateBurst       int                       // optional, requests allowed at once before RateLimit applies, defaults to 1
+
+	// Proxy and TLS settings, only used when neither HTTPClient nor Transport is set.
+	// Invalid settings make every request fail with the configuration error,
+	// ProcessRelease and ProcessReleases return it before any work starts.
+	ProxyURL           string            // optional, http://, https://, socks5:// or socks5h:// proxy, credentials as user:pass@
+	RootCAs            []byte            // optional, PEM encoded CA certificates trusted in addition to the system pool
+	ClientCertificates []tls.Certificate // optional, presented to the server or an HTTPS proxy on request
+	PinnedKeys         []string          // optional, base64 SHA-256 hashes of the public keys (SPKI) accepted for the CrowdNFO host
+
+	once         sync.Once
+	api          *api.Client
+	transportErr error // invalid proxy or TLS settings, see configError
+}
+
+// defaultClient is used whenever Options.Client is nil
+var defaultClient = &Client{}
+
+// NewClient returns a Client talking to the given base URL with default settings.
+// An empty baseURL selects https://crowdnfo.net.
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: baseURL}
+}
+
+// apiClient lazily builds the internal API client from the configuration
+func (c *Client) apiClient() *api.Client {
+	c.once.Do(func() {
+		httpClient := c.HTTPClient
+		if httpClient == nil {
+			transport, err := c.transport()
+			if err != nil {
+				c.transportErr = fmt.Errorf("invalid transport configuration: %w", err)
+				transport = api.NewErrorTransport(err)
+			}
+			httpClient = &http.Client{Transport: transport}
+		}
+		c.api = api.NewClient(api.Client{
+			BaseURL:         c.BaseURL,
+			HTTPClient:      httpClient,
+			UploadTimeout:   c.UploadTimeout,
+			FileListTimeout: c.FileListTimeout,
+			QueryTimeout:    c.QueryTimeout,
+			UserAgentSuffix: c.UserAgentSuffix,
+			Retry:           c.Retry,
+			NoticeCB:        c.NoticeCB,
+			QueueDir:        c.QueueDir,
+			QueueMaxAge:     c.QueueMaxAge,
+			RateLimit:       c.RateLimit,
+			RateBurst:       c.RateBurst,
+		})
+	})
+	return c.api
+}
+
+// configError returns the error of invalid proxy or TLS settings, which every request would fail with
+func (c *Client) configError() error {
+	c.apiClient()
+	return c.transportErr
+}
+
+// transport returns the configured transport or builds one from the proxy and TLS settings
+func (c *Client) transport() (http.RoundTripper, error) {
+	if c.Transport != nil {
+		return c.Transport, nil
+	}
+
+	cfg := api.TransportConfig{
+		ProxyURL:           c.ProxyURL,
+		RootCAs:            c.RootCAs,
+		ClientCertificates: c.ClientCertificates,
+		PinnedKeys:         c.PinnedKeys,
+	}
+	if cfg.IsZero() {
+		return nil, nil
+	}
+
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = api.DefaultBaseURL
+	}
+	if u, err := url.Parse(baseURL); err == nil {
+		cfg.PinnedHost = u.Hostname()
+	}
+
+	return api.NewTransport(cfg)
+}
+
+// Notice returns the latest update or deprecation notice announced by the server, or nil if there is none.
+func (c *Client) Notice() *typing.ServerNotice {
+	return c.apiClient().Notice()
+}
+
+// FlushQueue replays the uploads spooled to QueueDir using the client's API key.
+// Uploaded, rejected and expired entries are removed. Entries failing for any other
+// reason, e.g. an invalid API key or cancellation, stay queued for the next flush.
+func (c *Client) FlushQueue(ctx context.Context) (*typing.ProcessResult, error) {
+	apiKey, err := resolveAPIKey(ctx, c.Credentials, c.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.apiClient().FlushQueue(ctx, apiKey)
+	redact.Result(result, apiKey)
+	return result, redact.Error(err, apiKey)
+}
+
+// clientOrDefault returns the client to use for the given options
+func clientOrDefault(c *Client) *Client {
+	if c == nil {
+		return defaultClient
+	}
+	return c
+}
@@ -0,0 +1,86 @@
+package crowdnfo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTLSReleaseServer(t *testing.T) (*httptest.Server, []byte, string) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"releaseName":"Some.Release-GRP"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cert := server.Certificate()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return server, caPEM, base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestClientTLS(t *testing.T) {
+	server, caPEM, pin := newTLSReleaseServer(t)
+
+	tests := []struct {
+		name        string
+		client      *Client
+		expectError string
+	}{
+		{name: "Untrusted CA", client: &Client{BaseURL: server.URL}, expectError: "certificate"},
+		{name: "Extra root CA", client: &Client{BaseURL: server.URL, RootCAs: caPEM}},
+		{name: "Matching pin", client: &Client{BaseURL: server.URL, RootCAs: caPEM, PinnedKeys: []string{pin}}},
+		{name: "Mismatching pin", client: &Client{BaseURL: server.URL, RootCAs: caPEM, PinnedKeys: []string{"AAAA"}}, expectError: "pinned public key"},
+		{name: "Invalid root CA", client: &Client{BaseURL: server.URL, RootCAs: []byte("garbage")}, expectError: "invalid transport configuration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.client.Retry.MaxAttempts = 1
+			_, err := tt.client.GetRelease(context.Background(), "Some.Release-GRP")
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret")) {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		proxied = r.URL.String()
+		w.Write([]byte(`{"releaseName":"Some.Release-GRP"}`))
+	}))
+	defer proxy.Close()
+
+	client := &Client{
+		BaseURL:  "http://crowdnfo.invalid",
+		ProxyURL: strings.Replace(proxy.URL, "http://", "http://user:secret@", 1),
+	}
+	if _, err := client.GetRelease(context.Background(), "Some.Release-GRP"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if proxied != "http://crowdnfo.invalid/api/releases/Some.Release-GRP" {
+		t.Errorf("Unexpected proxied URL %q", proxied)
+	}
+
+	invalid := &Client{ProxyURL: "ftp://proxy.invalid"}
+	if _, err := invalid.GetRelease(context.Background(), "Some.Release-GRP"); err == nil || !strings.Contains(err.Error(), "unsupported proxy scheme") {
+		t.Errorf("Expected unsupported proxy scheme error, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package crowdnfo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/redact"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// ErrNoCredentials is returned when a credential provider has no API key to offer
+var ErrNoCredentials = errors.New("no API key available")
+
+// StaticCredentials provides a fixed API key
+type StaticCredentials string
+
+func (s StaticCredentials) APIKey(ctx context.Context) (string, error) {
+	if s == "" {
+		return "", ErrNoCredentials
+	}
+	return string(s), nil
+}
+
+// EnvCredentials reads the API key from an environment variable on every use
+type EnvCredentials string
+
+func (e EnvCredentials) APIKey(ctx context.Context) (string, error) {
+	key := strings.TrimSpace(os.Getenv(string(e)))
+	if key == "" {
+		return "", fmt.Errorf("environment variable %s: %w", string(e), ErrNoCredentials)
+	}
+	return key, nil
+}
+
+// FileCredentials reads the API key from a file, e.g. a Docker or Kubernetes secret.
+// The file is read again whenever its modification time or size changes, so rotated
+// secrets are picked up without a restart. Surrounding whitespace is ignored.
+type FileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	size    int64
+}
+
+// NewFileCredentials returns a provider reading the API key from path
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{path: path}
+}
+
+func (f *FileCredentials) APIKey(ctx context.Context) (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("API key file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.key != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.key, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("API key file: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("API key file %s: %w", f.path, ErrNoCredentials)
+	}
+
+	f.key, f.modTime, f.size = key, info.ModTime(), info.Size()
+	return key, nil
+}
+
+// CommandCredentials runs a command and uses its trimmed standard output as API key,
+// e.g. a password manager CLI. Output and error output are never included in errors.
+type CommandCredentials struct {
+	Name     string
+	Args     []string
+	CacheFor time.Duration // optional, reuse the key for this long instead of running the command every time
+
+	mu      sync.Mutex
+	key     string
+	fetched time.Time
+}
+
+// NewCommandCredentials returns a provider running the given command
+func NewCommandCredentials(name string, args ...string) *CommandCredentials {
+	return &CommandCredentials{Name: name, Args: args}
+}
+
+func (c *CommandCredentials) APIKey(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.key != "" && c.CacheFor > 0 && time.Since(c.fetched) < c.CacheFor {
+		return c.key, nil
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("API key command %s: %w", c.Name, err)
+	}
+	key := strings.TrimSpace(stdout.String())
+	if key == "" {
+		return "", fmt.Errorf("API key command %s: %w", c.Name, ErrNoCredentials)
+	}
+
+	c.key, c.fetched = key, time.Now()
+	return key, nil
+}
+
+// resolveAPIKey returns the key of the provider, or apiKey if there is none
+func resolveAPIKey(ctx context.Context, provider typing.CredentialProvider, apiKey string) (string, error) {
+	if provider == nil {
+		return apiKey, nil
+	}
+	return provider.APIKey(ctx)
+}
+
+// call resolves the client's API key and passes it to fn, removing the key from the returned error
+func (c *Client) call(ctx context.Context, fn func(apiKey string) error) error {
+	apiKey, err := resolveAPIKey(ctx, c.Credentials, c.APIKey)
+	if err != nil {
+		return err
+	}
+	return redact.Error(fn(apiKey), apiKey)
+}
+
+// withAPIKey is like Client.call for functions returning a value
+func withAPIKey[T any](ctx context.Context, c *Client, fn func(apiKey string) (T, error)) (T, error) {
+	var result T
+	err := c.call(ctx, func(apiKey string) (err error) {
+		result, err = fn(apiKey)
+		return err
+	})
+	return result, err
+}
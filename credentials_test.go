@@ -0,0 +1,77 @@
+package crowdnfo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("CROWDNFO_TEST_KEY", " env-key\n")
+
+	key, err := EnvCredentials("CROWDNFO_TEST_KEY").APIKey(context.Background())
+	if err != nil || key != "env-key" {
+		t.Errorf("Expected env-key, got %q (%v)", key, err)
+	}
+
+	if _, err := EnvCredentials("CROWDNFO_TEST_MISSING").APIKey(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestFileCredentialsReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_key")
+	if err := os.WriteFile(path, []byte("first-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	provider := NewFileCredentials(path)
+
+	key, err := provider.APIKey(context.Background())
+	if err != nil || key != "first-key" {
+		t.Fatalf("Expected first-key, got %q (%v)", key, err)
+	}
+
+	// A rotated secret is picked up on the next call
+	if err := os.WriteFile(path, []byte("second-key-rotated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	key, err = provider.APIKey(context.Background())
+	if err != nil || key != "second-key-rotated" {
+		t.Errorf("Expected second-key-rotated, got %q (%v)", key, err)
+	}
+
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.APIKey(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials for an empty file, got %v", err)
+	}
+}
+
+func TestCommandCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	key, err := NewCommandCredentials("sh", "-c", "echo command-key").APIKey(context.Background())
+	if err != nil || key != "command-key" {
+		t.Errorf("Expected command-key, got %q (%v)", key, err)
+	}
+
+	_, err = NewCommandCredentials("sh", "-c", "echo leaked-output; exit 1").APIKey(context.Background())
+	if err == nil || errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("Expected the command to fail, got %v", err)
+	}
+	if strings.Contains(err.Error(), "leaked-output") {
+		t.Errorf("Expected the command output to stay out of the error, got %v", err)
+	}
+}
@@ -1,18 +1,22 @@
 package crowdnfo
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"time"
 
 	"github.com/crowdnfo/crowdnfo-go/internal"
 	"github.com/crowdnfo/crowdnfo-go/internal/api"
 	"github.com/crowdnfo/crowdnfo-go/internal/files"
 	"github.com/crowdnfo/crowdnfo-go/internal/mediainfo"
+	"github.com/crowdnfo/crowdnfo-go/internal/redact"
 	"github.com/crowdnfo/crowdnfo-go/typing"
 )
 
@@ -23,9 +27,74 @@ type Options struct {
 	Category        string
 	NFOFilePath     string // optional
 	APIKey          string
+	Credentials     typing.CredentialProvider // optional, takes precedence over APIKey
 	ArchiveDir      string
 	MaxHashFileSize int64
-	ProgressCB      typing.ProgressCB
+	ProgressCB      typing.ProgressCB   // optional, string based progress, see EventCB
+	EventCB         typing.EventCB      // optional, structured progress events
+	Events          chan<- typing.Event // optional, receives the same events, must be drained while processing
+	Client          *Client             // optional, defaults to a shared client for https://crowdnfo.net
+
+	// SkipExisting queries CrowdNFO first and does not upload assets it already has.
+	SkipExisting bool
+	// SkipExistingWork additionally skips MediaInfo generation and hashing when their
+	// results are not needed anymore because the matching assets already exist.
+	SkipExistingWork bool
+
+	// Overrides for integrations that already know the exact files, e.g. from Sonarr or Radarr.
+	// Empty values fall back to the detection heuristics, NFOFilePath above is honored as well.
+	ReleaseName   string    // optional, used instead of the name of ReleasePath
+	MediaFilePath string    // optional, media file used for MediaInfo and hashing of a single release
+	FileListRoot  string    // optional, directory the file list of a single release is created from, defaults to ReleasePath
+	Episodes      []Episode // optional, processes the release as season pack with exactly these episodes
+
+	// Concurrency is the number of season pack episodes processed at once, defaults to 1.
+	// The other limits bound a single step across all episodes and default to Concurrency
+	// (times BatchConcurrency for ProcessReleases), e.g. several files can be hashed at once
+	// while uploads stay sequential.
+	Concurrency          int
+	HashConcurrency      int
+	MediaInfoConcurrency int
+	UploadConcurrency    int
+
+	// HashProgressInterval is the time between two hashing progress events with bytes done,
+	// throughput and ETA. Defaults to 1s, a negative value disables them.
+	HashProgressInterval time.Duration
+
+	// Hooks run custom steps before or after the pipeline steps, see Job
+	Hooks []Hook
+
+	// PayloadFilter inspects every MediaInfo, NFO and file list right before it is sent and
+	// can keep, modify or skip it. The reason it gives is recorded in the asset result.
+	PayloadFilter typing.PayloadFilter
+
+	// BatchConcurrency is the number of releases ProcessReleases processes at once, defaults to 1
+	BatchConcurrency int
+
+	// ReleaseNameMode checks the release name and the season pack episode names against the scene
+	// naming rules before any hashing or MediaInfo work starts, see ValidateReleaseName.
+	// Defaults to ReleaseNameAccept.
+	ReleaseNameMode ReleaseNameMode
+
+	// DryRun builds every payload without sending it. The payloads are returned in
+	// ProcessResult.DryRun and, if DryRunDir is set, written below DryRunDir/<release name>.
+	// APIKey is not required and nothing is archived.
+	DryRun    bool
+	DryRunDir string
+
+	// Uploader replaces the upload to CrowdNFO, e.g. with a DirUploader or a MultiUploader
+	// sending to CrowdNFO and another sink. The offline queue and ArchiveDir only apply
+	// to the built-in upload, APIKey is not required.
+	Uploader typing.Uploader
+}
+
+// Episode is a season pack episode given explicitly in Options.Episodes.
+// Without an NFO of its own, episode E01 gets Options.NFOFilePath as general NFO,
+// like in detected season packs. The other episodes are uploaded without NFO.
+type Episode struct {
+	ReleaseName   string // release name of the episode
+	MediaFilePath string // video file of the episode
+	NFOFilePath   string // optional, defaults to the NFO named like the video or the episode next to the video
 }
 
 // Valid CrowdNFO categories
@@ -45,112 +114,193 @@ var categoryRegexPatterns = []struct {
 	{`(?i)\b(mp3|flac|webflac|aac|wav|album|artist|discography|single|vinyl|cd|\d+bit|\d+khz)\b`, "Music"},
 }
 
+// hashChunkSize is the amount of data hashed between two cancellation checks
+const hashChunkSize = 4 * 1024 * 1024
+
+// defaultHashProgressInterval is the default time between two hashing progress events
+const defaultHashProgressInterval = time.Second
+
+// hashProgressInterval returns the configured interval of hashing progress events
+func hashProgressInterval(opts Options) time.Duration {
+	if opts.HashProgressInterval == 0 {
+		return defaultHashProgressInterval
+	}
+	return opts.HashProgressInterval
+}
+
 // ProcessRelease is the main entrypoint for uploading release info to CrowdNFO.
 func ProcessRelease(opts Options) (*typing.ProcessResult, error) {
+	return ProcessReleaseContext(context.Background(), opts)
+}
 
-	progressCB := opts.ProgressCB
-	if progressCB == nil {
-		progressCB = func(stage, releaseName, detail string) {}
+// ProcessReleaseContext is like ProcessRelease but stops hashing, MediaInfo and uploads once ctx is done.
+// When cancelled, the partial result collected so far is returned together with ctx.Err().
+func ProcessReleaseContext(ctx context.Context, opts Options) (*typing.ProcessResult, error) {
+	job, err := NewJob(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return job.Process(ctx)
+}
+
+// environment holds everything checked once per ProcessRelease or ProcessReleases call
+type environment struct {
+	client        *api.Client
+	apiKey        string
+	mediaInfoPath string // empty if MediaInfo is not available
+	limits        workLimits
+	emit          typing.EventCB // redacts the API key, safe for concurrent use
+}
+
+// newEnvironment resolves the API key and checks the client's proxy and TLS settings and MediaInfo.
+// The API key is not validated and
+// the client version is only refused if an earlier response already declared it unsupported.
+// releases is the number of releases processed at once.
+func newEnvironment(ctx context.Context, opts Options, releases int) (*environment, error) {
+	client := clientOrDefault(opts.Client).apiClient()
+
+	// Do not start any work if the server already refused this client version
+	if client.Unsupported() {
+		return nil, ErrUnsupportedVersion
+	}
+
+	// Nor if every request to CrowdNFO would fail because of the proxy or TLS settings
+	if (!opts.DryRun && opts.Uploader == nil) || opts.SkipExisting {
+		if err := clientOrDefault(opts.Client).configError(); err != nil {
+			return nil, err
+		}
+	}
+
+	apiKey, err := resolveAPIKey(ctx, opts.Credentials, opts.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == "" && !opts.DryRun && opts.Uploader == nil {
+		return nil, fmt.Errorf("API key is required")
 	}
 
 	mediaInfoPath := checkMediaInfoAvailable(opts.MediaInfoPath)
 
 	// Check MediaInfo version if available
 	if mediaInfoPath != "" {
-		if err := mediainfo.CheckMediaInfoVersion(mediaInfoPath); err != nil {
+		if err := mediainfo.CheckMediaInfoVersion(ctx, mediaInfoPath); err != nil {
 			return nil, fmt.Errorf("MediaInfo version check failed: %w", err)
 		}
 	}
 
-	releaseName := files.GetBaseOrName(opts.ReleasePath)
-	if releaseName == "" {
-		return nil, fmt.Errorf("Could not determine release name from path: %s", opts.ReleasePath)
-	}
+	return &environment{
+		client:        client,
+		apiKey:        apiKey,
+		mediaInfoPath: mediaInfoPath,
+		limits:        newWorkLimits(opts, releases),
+		emit:          newEmitter(ctx, opts, apiKey),
+	}, nil
+}
 
-	if opts.APIKey == "" {
-		return nil, fmt.Errorf("API key is required")
+// finish attaches the server notice to the result and makes sure the API key does not leak
+func (env *environment) finish(result *typing.ProcessResult, err error) (*typing.ProcessResult, error) {
+	if result != nil {
+		result.Notice = env.client.Notice()
 	}
-
-	category := getCategory(opts.Category, releaseName)
-	if category == "" {
-		return nil, fmt.Errorf("Invalid category: %s", category)
+	if err == nil && env.client.Unsupported() {
+		err = ErrUnsupportedVersion
 	}
 
-	// Check if this is a season pack
-	if internal.IsSeasonPack(releaseName) || internal.IsSeasonPackFallback(opts.ReleasePath) {
-		progressCB("startup", releaseName, "Detected Season Pack")
-		result, err := processSeasonPack(opts.APIKey, opts.ReleasePath, releaseName, category, opts.ArchiveDir, mediaInfoPath, opts.MaxHashFileSize, progressCB)
-		if err != nil {
-			return result, err
+	// The API key must never leak through warnings or errors, which often end up in logs
+	redact.Result(result, env.apiKey)
+	return result, redact.Error(err, env.apiKey)
+}
+
+// workLimits bounds how many files are hashed, analyzed by MediaInfo and uploaded at once
+type workLimits struct {
+	hash      internal.Semaphore
+	mediaInfo internal.Semaphore
+	upload    internal.Semaphore
+}
+
+// newWorkLimits builds the limits from the options, unset limits default to the
+// number of episodes that can be in flight across all releases processed at once
+func newWorkLimits(opts Options, releases int) workLimits {
+	concurrency := max(opts.Concurrency, 1) * max(releases, 1)
+	limit := func(n int) internal.Semaphore {
+		if n <= 0 {
+			n = concurrency
 		}
-		return result, nil
+		return internal.NewSemaphore(n)
+	}
+	return workLimits{
+		hash:      limit(opts.HashConcurrency),
+		mediaInfo: limit(opts.MediaInfoConcurrency),
+		upload:    limit(opts.UploadConcurrency),
 	}
+}
 
-	progressCB("startup", releaseName, "Detected Single Release")
+// uploadOptions collects the upload settings of a release or episode
+func uploadOptions(opts Options, category, hash string, existing api.ExistingAssets, progress reporter) api.UploadOptions {
+	return api.UploadOptions{
+		APIKey:     opts.APIKey,
+		Category:   category,
+		Hash:       hash,
+		ArchiveDir: opts.ArchiveDir,
+		Existing:   existing,
+		DryRun:     opts.DryRun,
+		DryRunDir:  opts.DryRunDir,
+		Uploader:   opts.Uploader,
+		Events:     progress.event,
+		Filter:     opts.PayloadFilter,
+	}
+}
 
-	result := &typing.ProcessResult{}
+// findMediaFile returns the media file of a single release, preferring opts.MediaFilePath
+func findMediaFile(opts Options) (string, error) {
+	if opts.MediaFilePath != "" {
+		if _, err := os.Stat(opts.MediaFilePath); err != nil {
+			return "", fmt.Errorf("Media file override: %w", err)
+		}
+		return opts.MediaFilePath, nil
+	}
 
 	mediaFile, err := files.FindBiggestFile(opts.ReleasePath)
 	if err != nil || mediaFile == "" {
 		mediaFile, err = files.FindFirstAudioFile(opts.ReleasePath)
 		if err != nil || mediaFile == "" {
-			return nil, fmt.Errorf("No media file found in: %s", opts.ReleasePath)
-		}
-	}
-
-	progressCB("metadata", releaseName, "Generating MediaInfo")
-	// Generate MediaInfo and hash if media file found
-	var mediaInfoJSON []byte
-	if mediaFile != "" && mediaInfoPath != "" {
-		// Generate MediaInfo JSON only for non-hash-only files
-		if !files.IsHashOnlyFile(mediaFile) {
-			mediaInfoJSON, err = mediainfo.GenerateMediaInfoJSON(mediaFile, mediaInfoPath)
-			if err != nil {
-				result.Warnings = append(result.Warnings, fmt.Errorf("%s - Failed to generate MediaInfo: %w", releaseName, err))
-			}
+			return "", fmt.Errorf("No media file found in: %s", opts.ReleasePath)
 		}
 	}
+	return mediaFile, nil
+}
 
-	progressCB("metadata", releaseName, "Finding NFO File")
-	nfoFile, err := files.FindNFOFile(opts.ReleasePath)
-	if err != nil {
-		result.Warnings = append(result.Warnings, fmt.Errorf("%s - No NFO File found", releaseName))
-		nfoFile = "" // Set empty string for upload function
-	}
+// seasonPackEpisodes returns the episodes given in opts.Episodes or detects them in the release directory
+func seasonPackEpisodes(opts Options, releaseName string, progress reporter) ([]files.EpisodeInfo, error) {
+	releasePath := opts.ReleasePath
 
-	var hash string
-	progressCB("hashing", releaseName, "Generating Hash")
-	// Calculate hash for any file found (media or ISO/IMG)
-	if mediaFile != "" {
-		shouldHash, err := shouldCalculateHash(mediaFile, opts.MaxHashFileSize)
-		if err != nil {
-			return result, err
-		} else if shouldHash {
-			hash, err = calculateSHA256(mediaFile)
-			if err != nil {
-				return result, err
+	if len(opts.Episodes) > 0 {
+		episodes := make([]files.EpisodeInfo, 0, len(opts.Episodes))
+		for _, episode := range opts.Episodes {
+			if episode.ReleaseName == "" || episode.MediaFilePath == "" {
+				return nil, fmt.Errorf("%s - Episode override needs a release name and a media file", releaseName)
+			}
+			if _, err := os.Stat(episode.MediaFilePath); err != nil {
+				return nil, fmt.Errorf("%s - Episode override: %w", episode.ReleaseName, err)
 			}
-		} else {
-			result.Warnings = append(result.Warnings, fmt.Errorf("%s - Skip Hashing: File exceeds max_hash_file_size limit", releaseName))
+			videoFile := files.VideoFile{
+				Path: episode.MediaFilePath,
+				Dir:  filepath.Dir(episode.MediaFilePath),
+				Name: filepath.Base(episode.MediaFilePath),
+			}
+			nfoFile := episode.NFOFilePath
+			if nfoFile == "" {
+				nfoFile = files.FindEpisodeNFO(videoFile, episode.ReleaseName, opts.NFOFilePath)
+			}
+			episodes = append(episodes, files.EpisodeInfo{
+				VideoFile:   videoFile,
+				ReleaseName: episode.ReleaseName,
+				NFOFile:     nfoFile,
+			})
 		}
+		return episodes, nil
 	}
 
-	progressCB("upload", releaseName, "Uploading")
-	uploadResult := api.UploadToCrowdNFO(opts.APIKey, releaseName, category, hash, opts.ReleasePath, mediaInfoJSON, nfoFile, opts.ArchiveDir, &progressCB)
-
-	result = internal.MergeProcessResults(result, uploadResult)
-
-	if err != nil {
-		return result, fmt.Errorf("upload to CrowdNFO failed: %w", err)
-	}
-
-	return result, nil
-}
-
-// processSeasonPack handles the processing of season packs
-func processSeasonPack(apiKey string, releasePath string, releaseName string, category string, archiveDir string, mediaInfoPath string, maxHashFileSize int64, progressCB typing.ProgressCB) (*typing.ProcessResult, error) {
-	result := &typing.ProcessResult{}
-
 	// Find all video files in the season pack
 	videoFiles, err := files.FindAllVideoFiles(releasePath)
 	if err != nil {
@@ -163,9 +313,12 @@ func processSeasonPack(apiKey string, releasePath string, releaseName string, ca
 
 	// Extract episode information for each video file
 	episodes := make([]files.EpisodeInfo, 0)
-	generalNFO := files.FindGeneralNFO(releasePath)
+	generalNFO := opts.NFOFilePath
+	if generalNFO == "" {
+		generalNFO = files.FindGeneralNFO(releasePath)
+	}
 
-	progressCB("metadata", releaseName, "Extracting Episodes")
+	progress.report(typing.StageMetadata, "Extracting Episodes")
 
 	for _, videoFile := range videoFiles {
 		episodeInfo := files.ExtractEpisodeInfo(videoFile, releaseName, generalNFO)
@@ -178,39 +331,33 @@ func processSeasonPack(apiKey string, releasePath string, releaseName string, ca
 		return nil, fmt.Errorf("%s - No fitting episodes found: %w", releaseName, err)
 	}
 
-	for _, episode := range episodes {
-
-		// Generate MediaInfo JSON for this episode
-		progressCB("metadata", episode.ReleaseName, "Generating MediaInfo")
-		var mediaInfoJSON []byte
-		if mediaInfoPath != "" {
-			mediaInfoJSON, err = mediainfo.GenerateMediaInfoJSON(episode.VideoFile.Path, mediaInfoPath)
-			if err != nil {
-				result.Warnings = append(result.Warnings, fmt.Errorf("%s - Failed to generate MediaInfo: %w", episode.ReleaseName, err))
-			}
-		}
+	return episodes, nil
+}
 
-		// Calculate SHA256 for this episode (check file size limit first)
-		progressCB("hashing", episode.ReleaseName, "Generating Hash")
-		var hash string
-		shouldHash, err := shouldCalculateHash(releasePath, maxHashFileSize)
-		if err != nil {
-			result.Warnings = append(result.Warnings, fmt.Errorf("%s - %w", episode.ReleaseName, err))
-		} else if shouldHash {
-			hash, err = calculateSHA256(episode.VideoFile.Path)
-			if err != nil {
-				result.Warnings = append(result.Warnings, fmt.Errorf("%s - Failed to generate Hash: %w", episode.ReleaseName, err))
-				continue
-			}
-		}
+// checkExistingAssets asks CrowdNFO which assets it already has if opts.SkipExisting is set.
+// A failed query is reported as warning and treated as if nothing exists.
+func checkExistingAssets(ctx context.Context, client *api.Client, opts Options, releaseName string, result *typing.ProcessResult, progress reporter) api.ExistingAssets {
+	if !opts.SkipExisting {
+		return api.ExistingAssets{}
+	}
 
-		// Upload this episode to CrowdNFO API with file list
-		progressCB("upload", episode.ReleaseName, "Uploading")
-		uploadResult := api.UploadEpisodeToCrowdNFO(apiKey, episode, category, hash, mediaInfoJSON, archiveDir, &progressCB)
-		result = internal.MergeProcessResults(result, uploadResult)
+	progress.report(typing.StageStartup, "Checking existing assets")
+	existing, err := client.GetExistingAssets(ctx, opts.APIKey, releaseName)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Errorf("%s - Failed to check existing assets: %w", releaseName, err))
+		return api.ExistingAssets{}
 	}
 
-	return result, nil
+	return existing
+}
+
+// skipExistingWork decides whether MediaInfo and hash can be skipped because their assets already exist.
+// The hash is only sent along with MediaInfo and NFO uploads.
+func skipExistingWork(opts Options, existing api.ExistingAssets) (skipMediaInfo bool, skipHash bool) {
+	if !opts.SkipExisting || !opts.SkipExistingWork {
+		return false, false
+	}
+	return existing.MediaInfo, existing.MediaInfo && existing.NFO
 }
 
 // matchCategoryByRegex tries to determine category from release name using built-in regex patterns
@@ -262,18 +409,44 @@ func shouldCalculateHash(filePath string, maxHashFileSize int64) (bool, error) {
 	return true, nil
 }
 
-func calculateSHA256(filePath string) (string, error) {
+// calculateSHA256 hashes the file in chunks and aborts with ctx.Err() once ctx is done.
+// The progress is reported every interval, a negative interval disables the reports.
+func calculateSHA256(ctx context.Context, filePath string, interval time.Duration, progress reporter) (string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", err
 	}
 	defer file.Close()
 
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
+	info, err := file.Stat()
+	if err != nil {
 		return "", err
 	}
 
+	var src io.Reader = file
+	var reader *progressReader
+	if interval >= 0 {
+		reader = newProgressReader(file, info.Size(), interval, progress.hashProgress)
+		src = reader
+	}
+
+	hash := sha256.New()
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		_, err := io.CopyN(hash, src, hashChunkSize)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if reader != nil {
+		reader.finish()
+	}
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
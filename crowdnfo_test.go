@@ -0,0 +1,770 @@
+package crowdnfo_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go"
+	"github.com/crowdnfo/crowdnfo-go/crowdnfotest"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+const movieName = "Some.Movie.2024.1080p.BluRay.x264-GRP"
+
+// writeMovieRelease creates a small movie release with a video file and an NFO
+func writeMovieRelease(t *testing.T) (string, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), movieName)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	video := []byte("not really a matroska file")
+	if err := os.WriteFile(filepath.Join(dir, "grp-somemovie.mkv"), video, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "grp-somemovie.nfo"), []byte("GRP PRESENTS"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(video)
+	return dir, hex.EncodeToString(sum[:])
+}
+
+func newTestOptions(t *testing.T, server *crowdnfotest.Server, releasePath string) crowdnfo.Options {
+	t.Helper()
+	mediaInfoPath, err := crowdnfotest.WriteFakeMediaInfo(t.TempDir(), "24.06", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crowdnfo.Options{
+		ReleasePath:   releasePath,
+		MediaInfoPath: mediaInfoPath,
+		APIKey:        "key",
+		Client:        server.Client("key"),
+	}
+}
+
+func TestProcessReleaseEndToEnd(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, hash := writeMovieRelease(t)
+
+	result, err := crowdnfo.ProcessRelease(newTestOptions(t, server, releasePath))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", result.Warnings)
+	}
+
+	uploads := server.Uploads()
+	if len(uploads) != 2 {
+		t.Fatalf("Expected 2 uploads, got %d", len(uploads))
+	}
+	for _, upload := range uploads {
+		if upload.ReleaseName != movieName || upload.Category != "Movies" || upload.FileHash != hash {
+			t.Errorf("Unexpected upload: %+v", upload)
+		}
+	}
+	if uploads[0].FileType != "MediaInfo" || string(uploads[0].Content) != crowdnfotest.DefaultMediaInfoJSON {
+		t.Errorf("Unexpected MediaInfo upload: %+v", uploads[0])
+	}
+	if uploads[1].FileType != "NFO" || uploads[1].OriginalFileName != "grp-somemovie.nfo" || string(uploads[1].Content) != "GRP PRESENTS" {
+		t.Errorf("Unexpected NFO upload: %+v", uploads[1])
+	}
+
+	fileLists := server.FileLists()
+	if len(fileLists) != 1 || len(fileLists[0].FileList.Entries) != 2 {
+		t.Fatalf("Expected a file list with 2 entries, got %+v", fileLists)
+	}
+
+	// A second run with SkipExisting does not upload anything again
+	opts := newTestOptions(t, server, releasePath)
+	opts.SkipExisting = true
+	result, err = crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, asset := range result.Assets {
+		if asset.Status != typing.AssetSkipped {
+			t.Errorf("Expected %s to be skipped, got %s", asset.AssetType, asset.Status)
+		}
+	}
+	if len(server.Uploads()) != 2 || len(server.FileLists()) != 1 {
+		t.Errorf("Expected no further uploads")
+	}
+}
+
+func TestProcessReleaseFaults(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, _ := writeMovieRelease(t)
+
+	server.AddFault(crowdnfotest.Fault{Endpoint: crowdnfotest.EndpointFiles, Status: http.StatusBadGateway})
+	server.AddFault(crowdnfotest.Fault{Endpoint: crowdnfotest.EndpointFileLists, Status: http.StatusTooManyRequests, RetryAfter: "0"})
+
+	result, err := crowdnfo.ProcessRelease(newTestOptions(t, server, releasePath))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", result.Warnings)
+	}
+	for _, asset := range result.Assets {
+		expected := 1
+		if asset.AssetType != "NFO" {
+			expected = 2
+		}
+		if asset.Status != typing.AssetUploaded || asset.Attempts != expected {
+			t.Errorf("Expected %s uploaded after %d attempts, got %+v", asset.AssetType, expected, asset)
+		}
+	}
+}
+
+func TestProcessReleaseUnauthorized(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, _ := writeMovieRelease(t)
+
+	opts := newTestOptions(t, server, releasePath)
+	opts.APIKey = "wrong"
+	result, err := crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 3 {
+		t.Fatalf("Expected 3 warnings, got %v", result.Warnings)
+	}
+	for _, warn := range result.Warnings {
+		var apiErr *crowdnfo.APIError
+		if !errors.Is(warn, crowdnfo.ErrUnauthorized) || !errors.As(warn, &apiErr) || apiErr.ReleaseName != movieName {
+			t.Errorf("Expected unauthorized APIError, got %v", warn)
+		}
+	}
+}
+
+func TestProcessReleaseDryRun(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, hash := writeMovieRelease(t)
+
+	opts := newTestOptions(t, server, releasePath)
+	opts.APIKey = ""
+	opts.DryRun = true
+	opts.DryRunDir = t.TempDir()
+
+	result, err := crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if server.Requests() != 0 {
+		t.Errorf("Expected no requests, got %d", server.Requests())
+	}
+	if len(result.DryRun) != 3 {
+		t.Fatalf("Expected 3 payloads, got %d", len(result.DryRun))
+	}
+	for _, asset := range result.Assets {
+		if asset.Status != typing.AssetDryRun {
+			t.Errorf("Expected %s to be %s, got %s", asset.AssetType, typing.AssetDryRun, asset.Status)
+		}
+	}
+
+	nfo := result.DryRun[1]
+	if nfo.Fields["FileHash"] != hash || nfo.Fields["OriginalFileName"] != "grp-somemovie.nfo" {
+		t.Errorf("Unexpected NFO fields: %v", nfo.Fields)
+	}
+	written, err := os.ReadFile(filepath.Join(opts.DryRunDir, movieName, "grp-somemovie.nfo"))
+	if err != nil {
+		t.Fatalf("Expected NFO payload on disk: %v", err)
+	}
+	if string(written) != "GRP PRESENTS" {
+		t.Errorf("Expected NFO content, got %q", written)
+	}
+	for _, name := range []string{"MediaInfo.request.json", "NFO.request.json", "FileList.json", "FileList.request.json"} {
+		if _, err := os.Stat(filepath.Join(opts.DryRunDir, movieName, name)); err != nil {
+			t.Errorf("Expected %s: %v", name, err)
+		}
+	}
+}
+
+func TestProcessReleaseMultiUploader(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, _ := writeMovieRelease(t)
+	dir := t.TempDir()
+
+	opts := newTestOptions(t, server, releasePath)
+	opts.Uploader = crowdnfo.MultiUploader(server.Client("key"), crowdnfo.NewDirUploader(dir))
+
+	result, err := crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", result.Warnings)
+	}
+	if len(server.Uploads()) != 2 || len(server.FileLists()) != 1 {
+		t.Errorf("Expected 2 uploads and a file list, got %d and %d", len(server.Uploads()), len(server.FileLists()))
+	}
+	for _, name := range []string{movieName + ".json", "grp-somemovie.nfo", "FileList.json"} {
+		if _, err := os.Stat(filepath.Join(dir, movieName, name)); err != nil {
+			t.Errorf("Expected %s: %v", name, err)
+		}
+	}
+
+	// Errors of one uploader are reported without stopping the others
+	opts.Uploader = crowdnfo.MultiUploader(server.Client("wrong"), crowdnfo.NewDirUploader(dir))
+	result, err = crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 3 || !errors.Is(result.Warnings[0], crowdnfo.ErrUnauthorized) {
+		t.Errorf("Expected 3 unauthorized warnings, got %v", result.Warnings)
+	}
+}
+
+func TestProcessResultReport(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, _ := writeMovieRelease(t)
+
+	result, err := crowdnfo.ProcessRelease(newTestOptions(t, server, releasePath))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	releases := result.Releases()
+	if len(releases) != 1 || releases[0].ReleaseName != movieName || len(releases[0].Assets) != 3 {
+		t.Fatalf("Expected one release with 3 assets, got %+v", releases)
+	}
+	for _, asset := range releases[0].Assets {
+		if asset.Status != typing.AssetUploaded || asset.StatusCode != http.StatusCreated || asset.Attempts != 1 || asset.BytesSent <= 0 {
+			t.Errorf("Unexpected asset report: %+v", asset)
+		}
+	}
+	if nfo := releases[0].Assets[1]; nfo.ID == "" {
+		t.Errorf("Expected the NFO ID returned by the server, got %+v", nfo)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded struct {
+		Warnings []string `json:"warnings"`
+		Releases []struct {
+			ReleaseName string               `json:"releaseName"`
+			Assets      []typing.AssetResult `json:"assets"`
+		} `json:"releases"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(decoded.Releases) != 1 || decoded.Releases[0].Assets[0].AssetType != "MediaInfo" || decoded.Releases[0].Assets[0].Status != typing.AssetUploaded {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+}
+
+func TestProcessReleaseNameMode(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "Some Movie (2024) 1080p BluRay x264 - GRP")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"movie.mkv": "video", "movie.nfo": "GRP PRESENTS"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := newTestOptions(t, server, dir)
+	opts.ReleaseNameMode = crowdnfo.ReleaseNameReject
+	_, err := crowdnfo.ProcessRelease(opts)
+	var nameErr *crowdnfo.ReleaseNameError
+	if !errors.Is(err, crowdnfo.ErrValidation) || !errors.As(err, &nameErr) || len(nameErr.Problems) == 0 {
+		t.Fatalf("Expected a release name error, got %v", err)
+	}
+	if server.Requests() != 0 {
+		t.Errorf("Expected no requests for a rejected name, got %d", server.Requests())
+	}
+
+	opts.ReleaseNameMode = crowdnfo.ReleaseNameNormalize
+	if _, err := crowdnfo.ProcessRelease(opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	uploads := server.Uploads()
+	if len(uploads) == 0 || uploads[0].ReleaseName != "Some.Movie.2024.1080p.BluRay.x264-GRP" {
+		t.Errorf("Expected uploads for the normalized name, got %+v", uploads)
+	}
+}
+
+func TestProcessReleaseRedactsAPIKey(t *testing.T) {
+	const apiKey = "super-secret-key"
+	server := crowdnfotest.NewServer(apiKey)
+	defer server.Close()
+	releasePath, _ := writeMovieRelease(t)
+	server.AddFault(crowdnfotest.Fault{Endpoint: crowdnfotest.EndpointFiles, Count: 2, Status: http.StatusBadRequest, Body: `{"message":"key ` + apiKey + ` may not upload"}`})
+
+	opts := newTestOptions(t, server, releasePath)
+	opts.APIKey = ""
+	opts.Credentials = crowdnfo.StaticCredentials(apiKey)
+	opts.Client = server.Client("")
+
+	result, err := crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %v", result.Warnings)
+	}
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning.Error(), apiKey) {
+			t.Errorf("Expected API key to be redacted, got %v", warning)
+		}
+		if !errors.Is(warning, crowdnfo.ErrValidation) {
+			t.Errorf("Expected redacted warning to match ErrValidation, got %v", warning)
+		}
+	}
+	for _, asset := range result.Assets {
+		if strings.Contains(asset.Error, apiKey) {
+			t.Errorf("Expected API key to be redacted, got %v", asset.Error)
+		}
+	}
+	if uploads := server.Uploads(); len(uploads) != 0 {
+		t.Errorf("Expected rejected uploads, got %d", len(uploads))
+	}
+	if fileLists := server.FileLists(); len(fileLists) != 1 || fileLists[0].APIKey != apiKey {
+		t.Errorf("Expected the file list to be uploaded with the provided key, got %+v", fileLists)
+	}
+}
+
+func TestProcessReleaseOverrides(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, _ := writeMovieRelease(t)
+
+	// A second, bigger file would win the heuristic, and the NFO lives outside the release
+	if err := os.WriteFile(filepath.Join(releasePath, "sample.mkv"), []byte("a much bigger sample file than the movie"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	nfoPath := filepath.Join(t.TempDir(), "from-indexer.nfo")
+	if err := os.WriteFile(nfoPath, []byte("INDEXER NFO"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mediaFile := filepath.Join(releasePath, "grp-somemovie.mkv")
+	sum := sha256.Sum256([]byte("not really a matroska file"))
+
+	opts := newTestOptions(t, server, releasePath)
+	opts.ReleaseName = "Other.Movie.2024.1080p.BluRay.x264-GRP"
+	opts.MediaFilePath = mediaFile
+	opts.NFOFilePath = nfoPath
+
+	if _, err := crowdnfo.ProcessRelease(opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	uploads := server.Uploads()
+	if len(uploads) != 2 {
+		t.Fatalf("Expected 2 uploads, got %d", len(uploads))
+	}
+	for _, upload := range uploads {
+		if upload.ReleaseName != opts.ReleaseName || upload.FileHash != hex.EncodeToString(sum[:]) {
+			t.Errorf("Unexpected upload: %+v", upload)
+		}
+	}
+	if uploads[1].OriginalFileName != "from-indexer.nfo" || string(uploads[1].Content) != "INDEXER NFO" {
+		t.Errorf("Expected the NFO override to be uploaded, got %+v", uploads[1])
+	}
+}
+
+func TestProcessReleaseInvalidTransport(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, _ := writeMovieRelease(t)
+
+	var started bool
+	opts := newTestOptions(t, server, releasePath)
+	opts.Client = &crowdnfo.Client{BaseURL: server.URL, ProxyURL: "ftp://proxy.invalid"}
+	opts.Hooks = []crowdnfo.Hook{{Step: crowdnfo.StepDetect, Before: func(ctx context.Context, job *crowdnfo.Job) error {
+		started = true
+		return nil
+	}}}
+
+	_, err := crowdnfo.ProcessRelease(opts)
+	if err == nil || !strings.Contains(err.Error(), "unsupported proxy scheme") {
+		t.Errorf("Expected the proxy configuration error, got %v", err)
+	}
+	if started {
+		t.Error("Expected no work before the configuration error")
+	}
+}
+
+func TestProcessReleaseEpisodeOverrides(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	dir := t.TempDir()
+	var episodes []crowdnfo.Episode
+	for _, name := range []string{"Some.Show.S01E01.1080p.WEB.h264-GRP", "Some.Show.S01E02.1080p.WEB.h264-GRP"} {
+		path := filepath.Join(dir, name+".mkv")
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		episodes = append(episodes, crowdnfo.Episode{ReleaseName: name, MediaFilePath: path})
+	}
+
+	opts := newTestOptions(t, server, dir)
+	opts.ReleaseName = "Some.Show.S01.1080p.WEB.h264-GRP"
+	opts.Episodes = episodes
+
+	result, err := crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	releases := result.Releases()
+	if len(releases) != 2 || releases[0].ReleaseName != episodes[0].ReleaseName || releases[1].ReleaseName != episodes[1].ReleaseName {
+		t.Fatalf("Expected one entry per episode, got %+v", releases)
+	}
+	for _, upload := range server.Uploads() {
+		sum := sha256.Sum256([]byte(upload.ReleaseName))
+		if upload.FileType != "MediaInfo" || upload.FileHash != hex.EncodeToString(sum[:]) {
+			t.Errorf("Unexpected upload: %+v", upload)
+		}
+	}
+}
+
+func TestProcessReleaseEpisodeNFOs(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	dir := t.TempDir()
+	var episodes []crowdnfo.Episode
+	for i := 1; i <= 3; i++ {
+		name := fmt.Sprintf("Some.Show.S01E%02d.1080p.WEB.h264-GRP", i)
+		path := filepath.Join(dir, fmt.Sprintf("e%02d.mkv", i))
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		episodes = append(episodes, crowdnfo.Episode{ReleaseName: name, MediaFilePath: path})
+	}
+	// E02 has an NFO next to its video, the general NFO only goes to E01
+	if err := os.WriteFile(filepath.Join(dir, "e02.nfo"), []byte("E02 NFO"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	generalNFO := filepath.Join(t.TempDir(), "grp.nfo")
+	if err := os.WriteFile(generalNFO, []byte("GENERAL NFO"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := newTestOptions(t, server, dir)
+	opts.ReleaseName = "Some.Show.S01.1080p.WEB.h264-GRP"
+	opts.NFOFilePath = generalNFO
+	opts.Episodes = episodes
+	if _, err := crowdnfo.ProcessRelease(opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	nfos := map[string]string{}
+	for _, upload := range server.Uploads() {
+		if upload.FileType == "NFO" {
+			nfos[upload.ReleaseName] = string(upload.Content)
+		}
+	}
+	want := map[string]string{episodes[0].ReleaseName: "GENERAL NFO", episodes[1].ReleaseName: "E02 NFO"}
+	if !maps.Equal(nfos, want) {
+		t.Errorf("Expected NFOs %v, got %v", want, nfos)
+	}
+}
+
+func TestProcessSeasonPackConcurrency(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	dir := t.TempDir()
+	var episodes []crowdnfo.Episode
+	for i := 1; i <= 4; i++ {
+		name := fmt.Sprintf("Some.Show.S01E%02d.1080p.WEB.h264-GRP", i)
+		path := filepath.Join(dir, name+".mkv")
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		episodes = append(episodes, crowdnfo.Episode{ReleaseName: name, MediaFilePath: path})
+	}
+	server.AddFault(crowdnfotest.Fault{Endpoint: crowdnfotest.EndpointFileLists, Count: 4, Latency: 500 * time.Millisecond})
+
+	// The callback is not synchronized, the race detector catches concurrent calls
+	var events []string
+	opts := newTestOptions(t, server, dir)
+	opts.ReleaseName = "Some.Show.S01.1080p.WEB.h264-GRP"
+	opts.Episodes = episodes
+	opts.Concurrency = 4
+	opts.HashConcurrency = 2
+	opts.ProgressCB = func(stage, releaseName, detail string) {
+		events = append(events, stage)
+	}
+
+	result, err := crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if server.MaxInFlight() < 2 {
+		t.Errorf("Expected episodes to be uploaded concurrently, at most %d requests were in flight", server.MaxInFlight())
+	}
+
+	releases := result.Releases()
+	if len(releases) != len(episodes) {
+		t.Fatalf("Expected %d releases, got %d", len(episodes), len(releases))
+	}
+	for i, release := range releases {
+		if release.ReleaseName != episodes[i].ReleaseName {
+			t.Errorf("Expected results in episode order, got %s at %d", release.ReleaseName, i)
+		}
+	}
+	if len(events) == 0 {
+		t.Errorf("Expected progress events")
+	}
+}
+
+func TestProcessReleaseEvents(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	dir := t.TempDir()
+	var episodes []crowdnfo.Episode
+	for i := 1; i <= 2; i++ {
+		name := fmt.Sprintf("Some.Show.S01E%02d.1080p.WEB.h264-GRP", i)
+		path := filepath.Join(dir, name+".mkv")
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		episodes = append(episodes, crowdnfo.Episode{ReleaseName: name, MediaFilePath: path})
+	}
+
+	var events []typing.Event
+	var legacy []string
+	channel := make(chan typing.Event, 100)
+	opts := newTestOptions(t, server, dir)
+	opts.ReleaseName = "Some.Show.S01.1080p.WEB.h264-GRP"
+	opts.Episodes = episodes
+	opts.EventCB = func(event typing.Event) { events = append(events, event) }
+	opts.Events = channel
+	opts.ProgressCB = func(stage, releaseName, detail string) { legacy = append(legacy, stage+" "+releaseName) }
+
+	if _, err := crowdnfo.ProcessRelease(opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(channel) != len(events) || len(legacy) != len(events) {
+		t.Errorf("Expected every sink to get all %d events, got %d and %d", len(events), len(channel), len(legacy))
+	}
+
+	uploaded := make(map[string]int)
+	for _, event := range events {
+		if event.ReleaseName != opts.ReleaseName {
+			t.Errorf("Expected season pack name in %+v", event)
+		}
+		if event.EpisodeName == "" {
+			continue
+		}
+		if event.EpisodeTotal != 2 || event.EpisodeName != episodes[event.EpisodeIndex-1].ReleaseName {
+			t.Errorf("Unexpected episode fields in %+v", event)
+		}
+		if event.Stage == typing.StageUpload && event.AssetType != "" && event.BytesDone > 0 {
+			uploaded[event.EpisodeName]++
+		}
+	}
+	for _, episode := range episodes {
+		if uploaded[episode.ReleaseName] != 2 {
+			t.Errorf("Expected 2 finished upload events for %s, got %d", episode.ReleaseName, uploaded[episode.ReleaseName])
+		}
+	}
+	if legacy[len(legacy)-1] != "upload "+episodes[1].ReleaseName {
+		t.Errorf("Expected the legacy callback to get the episode name, got %q", legacy[len(legacy)-1])
+	}
+}
+
+func TestProcessReleaseHashProgress(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, _ := writeMovieRelease(t)
+
+	// Several hash chunks, so the progress is reported while hashing
+	mediaFile := filepath.Join(releasePath, "grp-somemovie.mkv")
+	if err := os.WriteFile(mediaFile, make([]byte, 9<<20), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var hashing []typing.Event
+	opts := newTestOptions(t, server, releasePath)
+	opts.HashProgressInterval = time.Nanosecond
+	opts.EventCB = func(event typing.Event) {
+		if event.Stage == typing.StageHashing && event.BytesTotal > 0 {
+			hashing = append(hashing, event)
+		}
+	}
+
+	if _, err := crowdnfo.ProcessRelease(opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(hashing) < 2 {
+		t.Fatalf("Expected periodic hashing events, got %d", len(hashing))
+	}
+	for i, event := range hashing {
+		if event.BytesTotal != 9<<20 || (i > 0 && event.BytesDone < hashing[i-1].BytesDone) {
+			t.Errorf("Unexpected hashing event %+v", event)
+		}
+	}
+	last := hashing[len(hashing)-1]
+	if last.BytesDone != last.BytesTotal || last.ETA != 0 || !strings.HasPrefix(last.Detail, "Hashing 100%") {
+		t.Errorf("Expected a final hashing event, got %+v", last)
+	}
+
+	// A negative interval disables the reports
+	hashing = nil
+	opts.HashProgressInterval = -1
+	if _, err := crowdnfo.ProcessRelease(opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(hashing) != 0 {
+		t.Errorf("Expected no hashing progress, got %d events", len(hashing))
+	}
+}
+
+func TestProcessReleasePayloadFilter(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, _ := writeMovieRelease(t)
+
+	opts := newTestOptions(t, server, releasePath)
+	opts.PayloadFilter = func(ctx context.Context, payload typing.Payload) typing.PayloadDecision {
+		switch payload.AssetType {
+		case "MediaInfo":
+			return typing.PayloadDecision{Action: typing.PayloadSkip, Reason: "contains internal paths"}
+		case "NFO":
+			payload.Data = []byte(strings.ToLower(string(payload.Data)))
+			return typing.PayloadDecision{Action: typing.PayloadModify, Payload: payload, Reason: "lowercased"}
+		}
+		return typing.PayloadDecision{Action: typing.PayloadKeep}
+	}
+
+	result, err := crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	uploads := server.Uploads()
+	if len(uploads) != 1 || uploads[0].FileType != "NFO" || string(uploads[0].Content) != "grp presents" {
+		t.Fatalf("Expected only the modified NFO to be uploaded, got %+v", uploads)
+	}
+	if len(server.FileLists()) != 1 {
+		t.Errorf("Expected the file list to be kept, got %d", len(server.FileLists()))
+	}
+
+	want := map[string][2]string{
+		"MediaInfo": {typing.AssetFiltered, "contains internal paths"},
+		"NFO":       {typing.AssetUploaded, "lowercased"},
+		"FileList":  {typing.AssetUploaded, ""},
+	}
+	if len(result.Assets) != len(want) {
+		t.Fatalf("Expected %d assets, got %+v", len(want), result.Assets)
+	}
+	for _, asset := range result.Assets {
+		if got := [2]string{asset.Status, asset.Reason}; got != want[asset.AssetType] {
+			t.Errorf("Expected %s to be %v, got %v", asset.AssetType, want[asset.AssetType], got)
+		}
+	}
+}
+
+func TestProcessSeasonPackNameMode(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "episode.mkv")
+	if err := os.WriteFile(path, []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := newTestOptions(t, server, dir)
+	opts.ReleaseName = "Some.Show.S01.1080p.WEB.h264-GRP"
+	opts.Episodes = []crowdnfo.Episode{{ReleaseName: "Some Show S01E01 1080p WEB h264 - GRP", MediaFilePath: path}}
+
+	opts.ReleaseNameMode = crowdnfo.ReleaseNameReject
+	if _, err := crowdnfo.ProcessRelease(opts); !errors.Is(err, crowdnfo.ErrValidation) {
+		t.Fatalf("Expected the episode name to be rejected, got %v", err)
+	}
+	if server.Requests() != 0 {
+		t.Errorf("Expected no requests for a rejected episode name, got %d", server.Requests())
+	}
+
+	opts.ReleaseNameMode = crowdnfo.ReleaseNameNormalize
+	if _, err := crowdnfo.ProcessRelease(opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	uploads := server.Uploads()
+	if len(uploads) == 0 || uploads[0].ReleaseName != "Some.Show.S01E01.1080p.WEB.h264-GRP" {
+		t.Errorf("Expected uploads for the normalized episode name, got %+v", uploads)
+	}
+}
+
+func TestProcessDetectedSeasonPackNormalized(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "Some Show S01 1080p WEB h264 - GRP")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		name := fmt.Sprintf("Some Show S01E%02d 1080p WEB h264 - GRP.mkv", i)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := newTestOptions(t, server, dir)
+	opts.ReleaseNameMode = crowdnfo.ReleaseNameNormalize
+	result, err := crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", result.Warnings)
+	}
+
+	var names []string
+	for _, upload := range server.Uploads() {
+		if !slices.Contains(names, upload.ReleaseName) {
+			names = append(names, upload.ReleaseName)
+		}
+	}
+	slices.Sort(names)
+	if want := []string{"Some.Show.S01E01.1080p.WEB.h264-GRP", "Some.Show.S01E02.1080p.WEB.h264-GRP"}; !slices.Equal(names, want) {
+		t.Errorf("Expected uploads for %v, got %v", want, names)
+	}
+}
+
+func TestDirUploaderPathTraversal(t *testing.T) {
+	root := t.TempDir()
+	uploader := crowdnfo.NewDirUploader(filepath.Join(root, "catalog"))
+
+	err := uploader.UploadFileList(context.Background(), typing.FileList{ReleaseName: ".."})
+	if err == nil {
+		t.Error("Expected an error for the release name \"..\"")
+	}
+	if _, err := os.Stat(filepath.Join(root, "FileList.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing written outside the directory, got %v", err)
+	}
+}
@@ -0,0 +1,413 @@
+// Package crowdnfotest provides an in-process fake CrowdNFO server and a fake
+// MediaInfo executable for writing offline end-to-end tests.
+package crowdnfotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// Endpoints a Fault can be restricted to
+const (
+	EndpointRelease   = "release"   // GET /api/releases/{name}
+	EndpointFiles     = "files"     // POST /api/releases/{name}/files and file downloads
+	EndpointFileLists = "filelists" // POST and GET /api/releases/{name}/filelists
+	EndpointSearch    = "search"    // GET /api/releases
+)
+
+// Upload is a MediaInfo or NFO upload received by the server
+type Upload struct {
+	ReleaseName      string
+	APIKey           string
+	FileType         string
+	OriginalFileName string
+	Category         string
+	FileHash         string
+	FileName         string // name of the multipart file part
+	Content          []byte
+	Header           http.Header
+}
+
+// FileListUpload is a file list upload received by the server
+type FileListUpload struct {
+	ReleaseName string
+	APIKey      string
+	FileList    typing.FileList
+	Header      http.Header
+}
+
+// Fault describes a scripted failure. Faults are consumed in the order they were added,
+// each matching request takes one occurrence.
+type Fault struct {
+	Endpoint   string        // optional, one of the Endpoint constants, empty matches every request
+	Count      int           // number of requests affected, defaults to 1
+	Latency    time.Duration // delay before answering
+	Status     int           // status code to answer with, 0 to only add latency
+	RetryAfter string        // optional Retry-After header
+	Body       string        // optional response body, e.g. malformed JSON
+}
+
+type storedFile struct {
+	typing.ReleaseFile
+	content []byte
+}
+
+type storedRelease struct {
+	category string
+	files    []storedFile
+	fileList *typing.FileList
+}
+
+// Server is a fake CrowdNFO API. Uploaded assets are stored, so the read and
+// search endpoints and Options.SkipExisting work against it as well.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	apiKeys   []string
+	releases  map[string]*storedRelease
+	uploads   []Upload
+	fileLists []FileListUpload
+	faults    []Fault
+	requests  int
+	inFlight  int
+	maxFlight int
+	nextID    int
+}
+
+// NewServer starts a fake server accepting the given API keys. Without keys, every key is accepted.
+// The server is closed by calling Close.
+func NewServer(apiKeys ...string) *Server {
+	s := &Server{
+		apiKeys:  apiKeys,
+		releases: make(map[string]*storedRelease),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/releases", s.handle(EndpointSearch, s.search))
+	mux.HandleFunc("GET /api/releases/{name}", s.handle(EndpointRelease, s.getRelease))
+	mux.HandleFunc("POST /api/releases/{name}/files", s.handle(EndpointFiles, s.uploadFile))
+	mux.HandleFunc("GET /api/releases/{name}/files/{id}", s.handle(EndpointFiles, s.downloadFile))
+	mux.HandleFunc("POST /api/releases/{name}/filelists", s.handle(EndpointFileLists, s.uploadFileList))
+	mux.HandleFunc("GET /api/releases/{name}/filelists", s.handle(EndpointFileLists, s.getFileList))
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Client returns a crowdnfo.Client talking to this server using the given API key for reads.
+// Retries are sped up so scripted faults do not slow down tests.
+func (s *Server) Client(apiKey string) *crowdnfo.Client {
+	return &crowdnfo.Client{
+		BaseURL: s.URL,
+		APIKey:  apiKey,
+		Retry:   typing.RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond},
+	}
+}
+
+// AddFault schedules a failure for upcoming requests
+func (s *Server) AddFault(fault Fault) {
+	if fault.Count <= 0 {
+		fault.Count = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, fault)
+}
+
+// AddRelease stores a release as if its assets had been uploaded before
+func (s *Server) AddRelease(releaseName, category string, fileTypes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.release(releaseName, category)
+	for _, fileType := range fileTypes {
+		if fileType == "FileList" {
+			r.fileList = &typing.FileList{ReleaseName: releaseName, Category: category}
+			continue
+		}
+		s.nextID++
+		r.files = append(r.files, storedFile{ReleaseFile: typing.ReleaseFile{ID: strconv.Itoa(s.nextID), FileType: fileType}})
+	}
+}
+
+// Uploads returns the MediaInfo and NFO uploads received so far
+func (s *Server) Uploads() []Upload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.uploads)
+}
+
+// FileLists returns the file list uploads received so far
+func (s *Server) FileLists() []FileListUpload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.fileLists)
+}
+
+// Requests returns the number of requests received, including rejected ones
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// MaxInFlight returns the highest number of requests that were handled at the same time
+func (s *Server) MaxInFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.maxFlight
+}
+
+// handle wraps a handler with API key validation and fault injection
+func (s *Server) handle(endpoint string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		s.inFlight++
+		s.maxFlight = max(s.maxFlight, s.inFlight)
+		defer func() {
+			s.mu.Lock()
+			s.inFlight--
+			s.mu.Unlock()
+		}()
+		fault, faulted := s.takeFault(endpoint)
+		authorized := len(s.apiKeys) == 0 || slices.Contains(s.apiKeys, r.Header.Get("X-Api-Key"))
+		s.mu.Unlock()
+
+		if faulted {
+			if fault.Latency > 0 {
+				select {
+				case <-time.After(fault.Latency):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			if fault.Status != 0 {
+				io.Copy(io.Discard, r.Body)
+				if fault.RetryAfter != "" {
+					w.Header().Set("Retry-After", fault.RetryAfter)
+				}
+				w.WriteHeader(fault.Status)
+				io.WriteString(w, fault.Body)
+				return
+			}
+		}
+
+		if !authorized {
+			writeError(w, http.StatusUnauthorized, "invalid API key")
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// takeFault returns the first fault matching the endpoint, s.mu must be held
+func (s *Server) takeFault(endpoint string) (Fault, bool) {
+	for i, fault := range s.faults {
+		if fault.Endpoint != "" && fault.Endpoint != endpoint {
+			continue
+		}
+		s.faults[i].Count--
+		if s.faults[i].Count <= 0 {
+			s.faults = slices.Delete(s.faults, i, i+1)
+		}
+		return fault, true
+	}
+	return Fault{}, false
+}
+
+// release returns the stored release, creating it if needed, s.mu must be held
+func (s *Server) release(releaseName, category string) *storedRelease {
+	r, ok := s.releases[releaseName]
+	if !ok {
+		r = &storedRelease{category: category}
+		s.releases[releaseName] = r
+	}
+	if category != "" {
+		r.category = category
+	}
+	return r
+}
+
+func (s *Server) uploadFile(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid multipart form: %v", err))
+		return
+	}
+	file, header, err := r.FormFile("File")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "missing File")
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	upload := Upload{
+		ReleaseName:      r.PathValue("name"),
+		APIKey:           r.Header.Get("X-Api-Key"),
+		FileType:         r.FormValue("FileType"),
+		OriginalFileName: r.FormValue("OriginalFileName"),
+		Category:         r.FormValue("Category"),
+		FileHash:         r.FormValue("FileHash"),
+		FileName:         header.Filename,
+		Content:          content,
+		Header:           r.Header.Clone(),
+	}
+	if upload.FileType != "MediaInfo" && upload.FileType != "NFO" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid FileType %q", upload.FileType))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.uploads = append(s.uploads, upload)
+	s.nextID++
+	stored := storedFile{
+		ReleaseFile: typing.ReleaseFile{ID: strconv.Itoa(s.nextID), FileType: upload.FileType, OriginalFileName: upload.OriginalFileName, FileHash: upload.FileHash},
+		content:     content,
+	}
+	rel := s.release(upload.ReleaseName, upload.Category)
+	rel.files = append(rel.files, stored)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(stored.ReleaseFile)
+}
+
+func (s *Server) uploadFileList(w http.ResponseWriter, r *http.Request) {
+	var fileList typing.FileList
+	if err := json.NewDecoder(r.Body).Decode(&fileList); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON: %v", err))
+		return
+	}
+	if fileList.ReleaseName != r.PathValue("name") {
+		writeError(w, http.StatusBadRequest, "release name does not match URL")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fileLists = append(s.fileLists, FileListUpload{
+		ReleaseName: fileList.ReleaseName,
+		APIKey:      r.Header.Get("X-Api-Key"),
+		FileList:    fileList,
+		Header:      r.Header.Clone(),
+	})
+	s.release(fileList.ReleaseName, fileList.Category).fileList = &fileList
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) getRelease(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.PathValue("name")
+	rel, ok := s.releases[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "release not found")
+		return
+	}
+
+	json.NewEncoder(w).Encode(s.toRelease(name, rel))
+}
+
+func (s *Server) downloadFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rel, ok := s.releases[r.PathValue("name")]; ok {
+		for _, file := range rel.files {
+			if file.ID == r.PathValue("id") {
+				w.Write(file.content)
+				return
+			}
+		}
+	}
+	writeError(w, http.StatusNotFound, "file not found")
+}
+
+func (s *Server) getFileList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rel, ok := s.releases[r.PathValue("name")]
+	if !ok || rel.fileList == nil {
+		writeError(w, http.StatusNotFound, "file list not found")
+		return
+	}
+	json.NewEncoder(w).Encode(rel.fileList)
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	s.mu.Lock()
+	var matches []typing.ReleaseSummary
+	for name, rel := range s.releases {
+		if !strings.Contains(strings.ToLower(name), strings.ToLower(query.Get("search"))) {
+			continue
+		}
+		if category := query.Get("category"); category != "" && category != rel.category {
+			continue
+		}
+		release := s.toRelease(name, rel)
+		matches = append(matches, typing.ReleaseSummary{
+			ReleaseName:  name,
+			Category:     rel.category,
+			HasMediaInfo: release.File("MediaInfo") != nil,
+			HasNFO:       release.File("NFO") != nil,
+			HasFileList:  release.HasFileList,
+		})
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(matches, func(a, b typing.ReleaseSummary) int { return strings.Compare(a.ReleaseName, b.ReleaseName) })
+
+	result := typing.SearchPage{Page: page, PageSize: pageSize, TotalCount: len(matches)}
+	start := min((page-1)*pageSize, len(matches))
+	end := min(start+pageSize, len(matches))
+	result.Items = matches[start:end]
+
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) toRelease(name string, rel *storedRelease) typing.Release {
+	release := typing.Release{ReleaseName: name, Category: rel.category, HasFileList: rel.fileList != nil}
+	for _, file := range rel.files {
+		release.Files = append(release.Files, file.ReleaseFile)
+	}
+	return release
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
@@ -0,0 +1,57 @@
+package crowdnfotest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DefaultMediaInfoJSON is the output of the fake MediaInfo if none is given
+const DefaultMediaInfoJSON = `{"creatingLibrary":{"name":"MediaInfoLib","version":"24.06"},"media":{"track":[{"@type":"General","Format":"Matroska"}]}}`
+
+// WriteFakeMediaInfo writes a fake mediainfo executable to dir and returns its path.
+// It reports the given version (e.g. "24.06") for --Version and prints output
+// for any file it is asked to analyze. An empty output selects DefaultMediaInfoJSON.
+func WriteFakeMediaInfo(dir, version string, output []byte) (string, error) {
+	if len(output) == 0 {
+		output = []byte(DefaultMediaInfoJSON)
+	}
+
+	// Keep the output in a separate file so it never has to be quoted for the shell
+	outputPath := filepath.Join(dir, "mediainfo-output.json")
+	if err := os.WriteFile(outputPath, output, 0644); err != nil {
+		return "", err
+	}
+
+	var path, script string
+	if runtime.GOOS == "windows" {
+		path = filepath.Join(dir, "mediainfo.bat")
+		script = strings.Join([]string{
+			"@echo off",
+			`if "%1"=="--Version" (`,
+			"  echo MediaInfo Command line,",
+			fmt.Sprintf("  echo MediaInfoLib - v%s", version),
+			"  exit /b 0",
+			")",
+			fmt.Sprintf(`type "%s"`, outputPath),
+		}, "\r\n") + "\r\n"
+	} else {
+		path = filepath.Join(dir, "mediainfo")
+		script = strings.Join([]string{
+			"#!/bin/sh",
+			`if [ "$1" = "--Version" ]; then`,
+			"  echo 'MediaInfo Command line,'",
+			fmt.Sprintf("  echo 'MediaInfoLib - v%s'", version),
+			"  exit 0",
+			"fi",
+			fmt.Sprintf("cat '%s'", strings.ReplaceAll(outputPath, "'", `'\''`)),
+		}, "\n") + "\n"
+	}
+
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
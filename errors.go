@@ -0,0 +1,23 @@
+package crowdnfo
+
+import "github.com/crowdnfo/crowdnfo-go/typing"
+
+// APIError is returned when the CrowdNFO API answers with an unexpected status code.
+// Use errors.As to inspect it, the warnings in ProcessResult wrap it.
+type APIError = typing.APIError
+
+// ReleaseNameError is returned for release names violating the scene naming rules,
+// see ValidateReleaseName. It matches ErrValidation.
+type ReleaseNameError = typing.ReleaseNameError
+
+// Sentinel errors for errors.Is, matched by *APIError based on its status code.
+var (
+	ErrUnauthorized  = typing.ErrUnauthorized  // 401, 403
+	ErrRateLimited   = typing.ErrRateLimited   // 429
+	ErrAlreadyExists = typing.ErrAlreadyExists // 409
+	ErrValidation    = typing.ErrValidation    // 400, 422
+	ErrNotFound      = typing.ErrNotFound      // 404, or the release has no such asset
+
+	// ErrUnsupportedVersion is returned once the server declared this library version unsupported
+	ErrUnsupportedVersion = typing.ErrUnsupportedVersion
+)
@@ -9,13 +9,14 @@ import (
 
 func main() {
 	opts := crowdnfo.Options{
-		ReleasePath:     "", // path to the release directory
-		MediaInfoPath:   "", // path to mediainfo binary (optional, defaults to "mediainfo" in PATH)
-		Category:        "", // e.g., "TV", "Movies" (optional, auto-detected if empty)
-		NFOFilePath:     "", // path to the NFO file (optional, auto-detected if empty)
-		APIKey:          "", // your CrowdNFO API key
-		MaxHashFileSize: 0,  // max file size for hashing in bytes (0 for no limit, -1 for do not hash)
-		ArchiveDir:      "", // directory to archive uploaded metadata, empty for no archiving
+		ReleasePath:     "",    // path to the release directory
+		MediaInfoPath:   "",    // path to mediainfo binary (optional, defaults to "mediainfo" in PATH)
+		Category:        "",    // e.g., "TV", "Movies" (optional, auto-detected if empty)
+		NFOFilePath:     "",    // path to the NFO file (optional, auto-detected if empty)
+		APIKey:          "",    // your CrowdNFO API key
+		MaxHashFileSize: 0,     // max file size for hashing in bytes (0 for no limit, -1 for do not hash)
+		ArchiveDir:      "",    // directory to archive uploaded metadata, empty for no archiving
+		SkipExisting:    false, // do not upload assets CrowdNFO already has
 		ProgressCB: func(stage, releaseName, detail string) {
 			fmt.Printf("[%s]\t%s\n", stage, detail)
 		},
@@ -2,162 +2,190 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
-	"github.com/crowdnfo/crowdnfo-go/internal"
 	"github.com/crowdnfo/crowdnfo-go/internal/files"
-	"github.com/crowdnfo/crowdnfo-go/internal/version"
 	"github.com/crowdnfo/crowdnfo-go/typing"
 )
 
-var BASE_URL = "https://crowdnfo.net/api/releases"
-
 const (
 	MediaInfoType = "MediaInfo"
 	NFOType       = "NFO"
 	FileListType  = "FileList"
 )
 
-// UploadToCrowdNFO uploads release data to CrowdNFO.
-// On failure, returns an error. If multiple errors occurred, returns an *UploadError
-// which contains all error messages and the count of successful uploads.
-func UploadToCrowdNFO(apiKey string, releaseName, category, hash, releasePath string, mediaInfoJSON []byte, nfoFile, archiveDir string, progressCB *typing.ProgressCB) *typing.ProcessResult {
-	result := &typing.ProcessResult{}
-	fileListEntries, err := files.CreateFileList(releasePath, releaseName)
-	if err != nil {
-		result.Warnings = append(result.Warnings, fmt.Errorf("%s - Failed to create File List: %v", releaseName, err))
-		fileListEntries = nil
-	}
-	uploadResult := uploadAssets(apiKey, releaseName, category, hash, archiveDir, mediaInfoJSON, nfoFile, fileListEntries)
-	result = internal.MergeProcessResults(result, uploadResult)
-	return result
+// UploadOptions holds the settings shared by all uploads of a release or episode
+type UploadOptions struct {
+	APIKey     string
+	Category   string
+	Hash       string
+	ArchiveDir string
+	Existing   ExistingAssets       // assets not uploaded again but reported as skipped
+	DryRun     bool                 // build the payloads without sending them
+	DryRunDir  string               // optional, dry run payloads are written here
+	Uploader   typing.Uploader      // optional, replaces the upload to CrowdNFO
+	Events     typing.EventCB       // optional, fills in the release and episode of the events
+	Filter     typing.PayloadFilter // optional, may modify or veto every asset before it is sent
 }
 
-// UploadEpisodeToCrowdNFO uploads release data to CrowdNFO.
-// On failure, returns an error. If multiple errors occurred, returns an *UploadError
-// which contains all error messages and the count of successful uploads.
-func UploadEpisodeToCrowdNFO(apiKey string, episodeInfo files.EpisodeInfo, category, hash string, mediaInfoJSON []byte, archiveDir string, progressCB *typing.ProgressCB) *typing.ProcessResult {
-	result := &typing.ProcessResult{}
-	fileListEntries, err := files.CreateEpisodeFileList(episodeInfo)
-	if err != nil {
-		result.Warnings = append(result.Warnings, fmt.Errorf("%s - Failed to create File List: %v", episodeInfo.ReleaseName, err))
-		fileListEntries = nil
-	}
-	uploadResult := uploadAssets(apiKey, episodeInfo.ReleaseName, category, hash, archiveDir, mediaInfoJSON, episodeInfo.NFOFile, fileListEntries)
-	result = internal.MergeProcessResults(result, uploadResult)
-	return result
+// UploadAssets uploads the MediaInfo, NFO and file list of a release or season pack episode.
+// Failures are returned as warnings in the result.
+func (c *Client) UploadAssets(ctx context.Context, releaseName string, mediaInfoJSON []byte, nfoFile string, fileListEntries []files.FileListEntry, opts UploadOptions) *typing.ProcessResult {
+	return c.uploadAssets(withProgress(ctx, opts.Events), releaseName, mediaInfoJSON, nfoFile, fileListEntries, opts)
 }
 
-func uploadAssets(apiKey, releaseName, category, hash, archiveDir string, mediaInfoJSON []byte, nfoFile string, fileListEntries []files.FileListEntry) *typing.ProcessResult {
+func (c *Client) uploadAssets(ctx context.Context, releaseName string, mediaInfoJSON []byte, nfoFile string, fileListEntries []files.FileListEntry, opts UploadOptions) *typing.ProcessResult {
 	result := &typing.ProcessResult{}
+	category, hash, archiveDir := opts.Category, opts.Hash, opts.ArchiveDir
 	// MediaInfo
-	if len(mediaInfoJSON) > 0 {
-		if err := uploadFile(apiKey, releaseName, MediaInfoType, "", mediaInfoJSON, hash, category, archiveDir); err != nil {
-			result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: %v", releaseName, MediaInfoType, err))
+	if opts.Existing.MediaInfo {
+		result.Assets = append(result.Assets, skippedAsset(releaseName, MediaInfoType))
+	} else if len(mediaInfoJSON) > 0 {
+		entry := queueEntry{ReleaseName: releaseName, Category: category, Hash: hash, AssetType: MediaInfoType, Data: mediaInfoJSON, ArchiveDir: archiveDir}
+		var source uploadSource = bytesSource(mediaInfoJSON)
+		if reason, ok := filterFile(ctx, result, opts, &entry, &source); ok {
+			c.sendFile(ctx, result, opts, entry, source)
+			recordReason(result, reason)
 		}
 	}
+	// Stop early once cancelled, the caller reports ctx.Err()
+	if ctx.Err() != nil {
+		return result
+	}
 	// NFO
-	if nfoFile != "" {
-		nfoData, err := os.ReadFile(nfoFile)
+	if opts.Existing.NFO {
+		result.Assets = append(result.Assets, skippedAsset(releaseName, NFOType))
+	} else if nfoFile != "" {
+		nfoSource, err := newFileSource(nfoFile)
 		if err != nil {
-			result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: %v", releaseName, NFOType, err))
+			result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: %w", releaseName, NFOType, err))
 		} else {
-			nfoFileName := filepath.Base(nfoFile)
-			if err := uploadFile(apiKey, releaseName, NFOType, nfoFileName, nfoData, hash, category, archiveDir); err != nil {
-				result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: %v", releaseName, NFOType, err))
+			entry := queueEntry{ReleaseName: releaseName, Category: category, Hash: hash, AssetType: NFOType, OriginalFileName: filepath.Base(nfoFile), ArchiveDir: archiveDir, sourcePath: nfoFile}
+			var source uploadSource = nfoSource
+			if reason, ok := filterFile(ctx, result, opts, &entry, &source); ok {
+				c.sendFile(ctx, result, opts, entry, source)
+				recordReason(result, reason)
 			}
 		}
 	}
+	if ctx.Err() != nil {
+		return result
+	}
 	// FileList
-	if len(fileListEntries) > 0 {
+	if opts.Existing.FileList {
+		result.Assets = append(result.Assets, skippedAsset(releaseName, FileListType))
+	} else if len(fileListEntries) > 0 {
 		fileListRequest := files.FileListRequest{
 			ReleaseName: releaseName,
 			Category:    category,
 			Entries:     fileListEntries,
 		}
-		if err := uploadFileList(apiKey, fileListRequest); err != nil {
-			result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: %v", releaseName, FileListType, err))
+		if reason, ok := filterFileList(ctx, result, opts, &fileListRequest); ok {
+			c.sendFileList(ctx, result, opts, fileListRequest)
+			recordReason(result, reason)
 		}
 	}
 
 	return result
 }
 
-func uploadFile(apiKey string, releaseName, fileType, originalFileName string, fileData []byte, hash, category, archiveDir string) error {
-	url := fmt.Sprintf("%s/%s/files", BASE_URL, releaseName)
+func skippedAsset(releaseName, assetType string) typing.AssetResult {
+	return typing.AssetResult{ReleaseName: releaseName, AssetType: assetType, Status: typing.AssetSkipped}
+}
+
+// uploadFile streams a MediaInfo or NFO file to CrowdNFO, copying it to archiveDir on the way.
+// The returned asset describes the upload, its Status is left to the caller. A failing archive
+// copy is returned as archiveErr and does not fail the upload.
+func (c *Client) uploadFile(ctx context.Context, apiKey string, releaseName, fileType, originalFileName string, source uploadSource, hash, category, archiveDir string) (asset typing.AssetResult, archiveErr, err error) {
+	asset = typing.AssetResult{ReleaseName: releaseName, AssetType: fileType}
+	start := time.Now()
+	defer func() { asset.Duration = time.Since(start) }()
+
+	url := c.releasesURL(releaseName, "files")
 
 	// Create multipart form
-	var b bytes.Buffer
-	writer := multipart.NewWriter(&b)
+	form := newFileForm(releaseName, fileType, originalFileName, source, hash, category)
 
-	// Add form fields
-	writer.WriteField("FileType", fileType)
-	if originalFileName != "" {
-		writer.WriteField("OriginalFileName", originalFileName)
-	}
-	if category != "" {
-		writer.WriteField("Category", category)
-	}
-	if hash != "" {
-		writer.WriteField("FileHash", hash)
+	// The archive copy is written while sending and only kept if the upload succeeds.
+	// Every upload gets its own temporary file, concurrent episodes may share an NFO name.
+	var archiveFile string
+	if archiveDir != "" {
+		archiveFile = filepath.Join(archiveDir, getFileName(fileType, releaseName, originalFileName))
+		if tmp, err := os.CreateTemp(archiveDir, filepath.Base(archiveFile)+".*.part"); err != nil {
+			archiveErr = fmt.Errorf("failed to archive uploaded %s file: %w", fileType, err)
+		} else {
+			tmp.Close()
+			form.archivePath = tmp.Name()
+			defer os.Remove(form.archivePath)
+		}
 	}
 
-	// Add file
-	part, err := writer.CreateFormFile("File", getFileName(fileType, releaseName, originalFileName))
+	body, err := form.Open()
 	if err != nil {
-		return fmt.Errorf("create form file: %w", err)
+		return asset, nil, fmt.Errorf("open %s: %w", fileType, err)
 	}
-	part.Write(fileData)
-
-	writer.Close()
 
 	// Create request
-	req, err := http.NewRequest("POST", url, &b)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
 	if err != nil {
-		return fmt.Errorf("create HTTP request: %w", err)
+		body.Close()
+		return asset, nil, fmt.Errorf("create HTTP request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("X-Api-Key", apiKey)
-	req.Header.Set("User-Agent", getUserAgent())
+	req.Header.Set("Content-Type", form.contentType())
+	req.ContentLength = form.contentLength()
+	req.GetBody = form.Open
+	asset.BytesSent = max(req.ContentLength, 0)
 
 	// Send request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, cancel, attempts, err := c.do(req, apiKey, c.UploadTimeout)
+	asset.Attempts = attempts
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
+		return asset, nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
+	defer cancel()
 	defer resp.Body.Close()
-
-	// Check for update headers
-	//checkUpdateHeaders(resp.Header)
+	asset.StatusCode = resp.StatusCode
 
 	// Read response body for error details
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return asset, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(body))
+		return asset, nil, newAPIError(resp.StatusCode, operationUpload, fileType, releaseName, respBody)
 	}
+	asset.ID, asset.URL = parseUploadResponse(respBody)
 
-	// Archive the uploaded file
-	if archiveDir != "" {
-		archiveFile := filepath.Join(archiveDir, getFileName(fileType, releaseName, originalFileName))
-		if err := os.WriteFile(archiveFile, fileData, 0644); err != nil {
-			return fmt.Errorf("failed to archive uploaded %s file: %w", fileType, err)
+	// Keep the archived copy of the uploaded file
+	if form.archivePath != "" {
+		if err := form.wait(); err != nil {
+			archiveErr = fmt.Errorf("failed to archive uploaded %s file: %w", fileType, err)
+		} else if err := os.Rename(form.archivePath, archiveFile); err != nil {
+			archiveErr = fmt.Errorf("failed to archive uploaded %s file: %w", fileType, err)
 		}
 	}
 
-	return nil
+	return asset, archiveErr, nil
+}
+
+// newFileForm builds the multipart form of a MediaInfo or NFO upload
+func newFileForm(releaseName, fileType, originalFileName string, source uploadSource, hash, category string) *multipartBody {
+	form := newMultipartBody("File", getFileName(fileType, releaseName, originalFileName), source)
+	form.addField("FileType", fileType)
+	form.addField("OriginalFileName", originalFileName)
+	form.addField("Category", category)
+	form.addField("FileHash", hash)
+	return form
 }
 
 func getFileName(fileType, releaseName, originalFileName string) string {
@@ -167,49 +195,69 @@ func getFileName(fileType, releaseName, originalFileName string) string {
 	return fmt.Sprintf("%s.json", releaseName)
 }
 
-// uploadFileList uploads a file list to CrowdNFO
-func uploadFileList(apiKey string, fileListRequest files.FileListRequest) error {
-	url := fmt.Sprintf("%s/%s/filelists", BASE_URL, fileListRequest.ReleaseName)
+// uploadFileList uploads a file list to CrowdNFO.
+// The returned asset describes the upload, its Status is left to the caller.
+func (c *Client) uploadFileList(ctx context.Context, apiKey string, fileListRequest files.FileListRequest) (asset typing.AssetResult, err error) {
+	asset = typing.AssetResult{ReleaseName: fileListRequest.ReleaseName, AssetType: FileListType}
+	start := time.Now()
+	defer func() { asset.Duration = time.Since(start) }()
+
+	url := c.releasesURL(fileListRequest.ReleaseName, "filelists")
 
 	// Convert to JSON
 	jsonData, err := json.Marshal(fileListRequest)
 	if err != nil {
-		return fmt.Errorf("failed to marshal file list: %w", err)
+		return asset, fmt.Errorf("failed to marshal file list: %w", err)
 	}
 
 	// Create request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("create HTTP request: %w", err)
+		return asset, fmt.Errorf("create HTTP request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Api-Key", apiKey)
-	req.Header.Set("User-Agent", getUserAgent())
+	asset.BytesSent = int64(len(jsonData))
 
 	// Send request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, cancel, attempts, err := c.do(req, apiKey, c.FileListTimeout)
+	asset.Attempts = attempts
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
+		return asset, fmt.Errorf("HTTP request failed: %w", err)
 	}
+	defer cancel()
 	defer resp.Body.Close()
+	asset.StatusCode = resp.StatusCode
+
+	body, _ := io.ReadAll(resp.Body)
 
 	// Check response
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		if resp.StatusCode == http.StatusUnauthorized {
-			return fmt.Errorf("unauthorized: please check your API key in config.json")
-		}
-		if resp.StatusCode == http.StatusBadRequest {
-			return fmt.Errorf("%s", string(body))
-		}
-		return fmt.Errorf("file list upload failed with status %d: %s", resp.StatusCode, string(body))
+		return asset, newAPIError(resp.StatusCode, operationUpload, FileListType, fileListRequest.ReleaseName, body)
 	}
+	asset.ID, asset.URL = parseUploadResponse(body)
 
-	return nil
+	return asset, nil
 }
 
-func getUserAgent() string {
-	return fmt.Sprintf("crowdnfo-go/%s", version.Version)
+// parseUploadResponse extracts the ID and URL CrowdNFO assigned to an uploaded asset.
+// Both are optional, responses without them are not an error.
+func parseUploadResponse(body []byte) (id, url string) {
+	var payload struct {
+		ID  json.RawMessage `json:"id"`
+		URL string          `json:"url"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", ""
+	}
+
+	// IDs may be sent as number or string
+	id = string(payload.ID)
+	if unquoted, err := strconv.Unquote(id); err == nil {
+		id = unquoted
+	}
+	if id == "null" {
+		id = ""
+	}
+	return id, payload.URL
 }
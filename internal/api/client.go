@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/version"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+const (
+	DefaultBaseURL         = "https://crowdnfo.net"
+	DefaultUploadTimeout   = 30 * time.Second
+	DefaultFileListTimeout = 30 * time.Second
+	DefaultQueryTimeout    = 30 * time.Second
+)
+
+// Client performs the HTTP calls against the CrowdNFO API.
+// All fields are read-only once the client is in use.
+type Client struct {
+	BaseURL         string
+	HTTPClient      *http.Client
+	UploadTimeout   time.Duration
+	FileListTimeout time.Duration
+	QueryTimeout    time.Duration
+	UserAgentSuffix string
+	Retry           typing.RetryPolicy
+	NoticeCB        typing.NoticeCB
+	QueueDir        string // optional, spool directory for uploads failing temporarily
+	QueueMaxAge     time.Duration
+	RateLimit       float64 // optional, requests per second shared by all requests
+	RateBurst       int
+
+	notices *noticeState
+	limiter *rateLimiter
+}
+
+// NewClient returns a copy of cfg with all unset values replaced by their defaults.
+func NewClient(cfg Client) *Client {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{}
+	}
+	if cfg.UploadTimeout <= 0 {
+		cfg.UploadTimeout = DefaultUploadTimeout
+	}
+	if cfg.FileListTimeout <= 0 {
+		cfg.FileListTimeout = DefaultFileListTimeout
+	}
+	if cfg.QueryTimeout <= 0 {
+		cfg.QueryTimeout = DefaultQueryTimeout
+	}
+	if cfg.QueueMaxAge <= 0 {
+		cfg.QueueMaxAge = DefaultQueueMaxAge
+	}
+	cfg.Retry = normalizeRetryPolicy(cfg.Retry)
+	cfg.notices = &noticeState{}
+	cfg.limiter = newRateLimiter(cfg.RateLimit, cfg.RateBurst)
+
+	return &cfg
+}
+
+// releasesURL builds the URL of a release or one of its endpoints below /api/releases.
+// The release name and all path segments are escaped.
+func (c *Client) releasesURL(releaseName string, segments ...string) string {
+	var b strings.Builder
+	b.WriteString(c.BaseURL)
+	b.WriteString("/api/releases/")
+	b.WriteString(url.PathEscape(releaseName))
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(url.PathEscape(segment))
+	}
+	return b.String()
+}
+
+// do sends the request with the common headers set, retrying transient failures
+// according to the retry policy. The timeout applies to every single attempt.
+// It returns the number of attempts made. The returned cancel func must be called
+// once the response body has been consumed.
+func (c *Client) do(req *http.Request, apiKey string, timeout time.Duration) (*http.Response, context.CancelFunc, int, error) {
+	if c.Unsupported() {
+		closeBody(req)
+		return nil, nil, 0, typing.ErrUnsupportedVersion
+	}
+
+	req.Header.Set("X-Api-Key", apiKey)
+	req.Header.Set("User-Agent", c.userAgent())
+
+	parent := req.Context()
+	for attempt := 1; ; attempt++ {
+		// Wait for the shared rate limiter before producing the body of this attempt
+		if delay := c.limiter.reserve(); delay > 0 {
+			reportProgress(parent, typing.Event{Stage: typing.StageRateLimit, Detail: fmt.Sprintf("Waiting %s for rate limit", delay.Round(time.Millisecond))})
+			if err := sleepContext(parent, delay); err != nil {
+				if attempt == 1 {
+					closeBody(req)
+				}
+				return nil, nil, attempt - 1, err
+			}
+		}
+
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, nil, attempt - 1, err
+			}
+			attemptReq = req.Clone(parent)
+			attemptReq.Body = body
+		}
+
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		resp, err := c.HTTPClient.Do(attemptReq.WithContext(ctx))
+		if err == nil {
+			if err := c.checkUpdateHeaders(resp.Header); err != nil {
+				drainAndClose(resp)
+				cancel()
+				return nil, nil, attempt, err
+			}
+		}
+
+		// Only requests with a replayable body can be retried
+		retry, delay := c.shouldRetry(parent, attempt, resp, err)
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			c.limiter.throttle(delay)
+		} else if err == nil {
+			c.limiter.recover()
+		}
+		if retry && (req.Body == nil || req.GetBody != nil) {
+			if resp != nil {
+				drainAndClose(resp)
+			}
+			cancel()
+			if err := sleepContext(parent, delay); err != nil {
+				return nil, nil, attempt, err
+			}
+			continue
+		}
+
+		if err != nil {
+			cancel()
+			return nil, nil, attempt, err
+		}
+		return resp, cancel, attempt, nil
+	}
+}
+
+// closeBody closes the body of a request that is not handed to the transport,
+// which stops the writer goroutine and closes the file of a streamed upload
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+func (c *Client) userAgent() string {
+	userAgent := fmt.Sprintf("crowdnfo-go/%s", version.Version)
+	if c.UserAgentSuffix != "" {
+		userAgent += " " + c.UserAgentSuffix
+	}
+	return userAgent
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/files"
+	"github.com/crowdnfo/crowdnfo-go/internal/redact"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// dryRunFile records the multipart upload of a MediaInfo or NFO file instead of sending it.
+// If opts.DryRunDir is set, the file content and its form fields are written below it.
+func (c *Client) dryRunFile(result *typing.ProcessResult, releaseName, fileType, originalFileName string, source uploadSource, hash, category string, opts UploadOptions) error {
+	form := newFileForm(releaseName, fileType, originalFileName, source, hash, category)
+
+	content, err := source.Open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	payload := typing.DryRunPayload{
+		ReleaseName: releaseName,
+		AssetType:   fileType,
+		Method:      "POST",
+		URL:         c.releasesURL(releaseName, "files"),
+		Fields:      make(map[string]string),
+		FileName:    form.fileName,
+		Body:        data,
+	}
+	for _, field := range form.fields {
+		payload.Fields[field[0]] = field[1]
+	}
+
+	if opts.DryRunDir != "" {
+		payload.Path, err = dryRunPath(opts.DryRunDir, releaseName, form.fileName)
+		if err != nil {
+			return err
+		}
+		if err := writeDryRunPayload(payload, opts.APIKey); err != nil {
+			return err
+		}
+	}
+
+	result.DryRun = append(result.DryRun, payload)
+	return nil
+}
+
+// dryRunFileList records the JSON upload of a file list instead of sending it.
+// If opts.DryRunDir is set, the request body is written to <dir>/<release name>/FileList.json.
+func (c *Client) dryRunFileList(result *typing.ProcessResult, fileListRequest files.FileListRequest, opts UploadOptions) error {
+	data, err := json.MarshalIndent(fileListRequest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal file list: %w", err)
+	}
+
+	payload := typing.DryRunPayload{
+		ReleaseName: fileListRequest.ReleaseName,
+		AssetType:   FileListType,
+		Method:      "POST",
+		URL:         c.releasesURL(fileListRequest.ReleaseName, "filelists"),
+		Body:        data,
+	}
+
+	if opts.DryRunDir != "" {
+		payload.Path, err = dryRunPath(opts.DryRunDir, fileListRequest.ReleaseName, FileListType+".json")
+		if err != nil {
+			return err
+		}
+		if err := writeDryRunPayload(payload, opts.APIKey); err != nil {
+			return err
+		}
+	}
+
+	result.DryRun = append(result.DryRun, payload)
+	return nil
+}
+
+// dryRunPath returns <dir>/<release name>/<file name>. Both names are reduced to their last
+// element, so names containing separators or ".." cannot write outside dir.
+func dryRunPath(dir, releaseName, fileName string) (string, error) {
+	releaseName, fileName = filepath.Base(releaseName), filepath.Base(fileName)
+	for _, name := range []string{releaseName, fileName} {
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			return "", fmt.Errorf("invalid dry run file name %q", name)
+		}
+	}
+	return filepath.Join(dir, releaseName, fileName), nil
+}
+
+// writeDryRunPayload writes the body to payload.Path and the request description next to it.
+// The API key never appears in the request description.
+func writeDryRunPayload(payload typing.DryRunPayload, apiKey string) error {
+	if err := os.MkdirAll(filepath.Dir(payload.Path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(payload.Path, payload.Body, 0644); err != nil {
+		return err
+	}
+
+	request, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	requestPath := filepath.Join(filepath.Dir(payload.Path), payload.AssetType+".request.json")
+	return os.WriteFile(requestPath, []byte(redact.String(string(request), apiKey)), 0644)
+}
+
+// recordDryRun records the outcome of a dry run upload
+func recordDryRun(result *typing.ProcessResult, releaseName, assetType string, err error) {
+	asset := typing.AssetResult{ReleaseName: releaseName, AssetType: assetType, Status: typing.AssetDryRun}
+	if err != nil {
+		asset.Status = typing.AssetFailed
+		asset.Error = err.Error()
+		result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: dry run failed: %w", releaseName, assetType, err))
+	}
+	result.Assets = append(result.Assets, asset)
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/files"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+func TestDryRunPathTraversal(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "dry-run")
+	client := NewClient(Client{})
+	opts := UploadOptions{DryRun: true, DryRunDir: dir}
+
+	result := &typing.ProcessResult{}
+	if err := client.dryRunFile(result, "../../escaped", NFOType, "../../../escaped.nfo", bytesSource("NFO"), "", "Movies", opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.dryRunFileList(result, files.FileListRequest{ReleaseName: "../escaped", Category: "Movies"}, opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, payload := range result.DryRun {
+		if !strings.HasPrefix(payload.Path, dir+string(filepath.Separator)) {
+			t.Errorf("Expected %s below %s", payload.Path, dir)
+		}
+	}
+	if entries, _ := os.ReadDir(root); len(entries) != 1 {
+		t.Errorf("Expected only the dry run directory in %s, got %v", root, entries)
+	}
+
+	if err := client.dryRunFile(result, "..", NFOType, "release.nfo", bytesSource("NFO"), "", "Movies", opts); err == nil {
+		t.Error("Expected an error for the release name \"..\"")
+	}
+}
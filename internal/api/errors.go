@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// Operations reported in APIError
+const (
+	operationUpload   = "upload"
+	operationDownload = "download"
+	operationQuery    = "query"
+)
+
+// newAPIError builds a typed error from a failed response
+func newAPIError(statusCode int, operation, assetType, releaseName string, body []byte) *typing.APIError {
+	message := parseServerMessage(body)
+	if message == "" && statusCode == http.StatusUnauthorized {
+		message = "invalid or missing API key"
+	}
+
+	return &typing.APIError{
+		StatusCode:  statusCode,
+		Operation:   operation,
+		AssetType:   assetType,
+		ReleaseName: releaseName,
+		Message:     message,
+	}
+}
+
+// parseServerMessage extracts a human readable message from an error response body.
+// JSON bodies are searched for the usual message fields, anything else is returned as is.
+func parseServerMessage(body []byte) string {
+	trimmed := strings.TrimSpace(string(body))
+	if trimmed == "" {
+		return ""
+	}
+
+	var payload struct {
+		Message string `json:"message"`
+		Detail  string `json:"detail"`
+		Title   string `json:"title"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(trimmed), &payload); err == nil {
+		for _, message := range []string{payload.Message, payload.Detail, payload.Error, payload.Title} {
+			if message != "" {
+				return message
+			}
+		}
+	}
+
+	return trimmed
+}
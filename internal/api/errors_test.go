@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name            string
+		statusCode      int
+		body            string
+		expectedMessage string
+		expectedKind    error
+	}{
+		{name: "Unauthorized without body", statusCode: 401, body: "", expectedMessage: "invalid or missing API key", expectedKind: typing.ErrUnauthorized},
+		{name: "Rate limited", statusCode: 429, body: "slow down", expectedMessage: "slow down", expectedKind: typing.ErrRateLimited},
+		{name: "Conflict with JSON message", statusCode: 409, body: `{"message":"NFO already exists"}`, expectedMessage: "NFO already exists", expectedKind: typing.ErrAlreadyExists},
+		{name: "Problem details", statusCode: 400, body: `{"title":"One or more validation errors occurred.","status":400}`, expectedMessage: "One or more validation errors occurred.", expectedKind: typing.ErrValidation},
+		{name: "Server error", statusCode: 500, body: "  oops\n", expectedMessage: "oops", expectedKind: nil},
+	}
+
+	sentinels := []error{typing.ErrUnauthorized, typing.ErrRateLimited, typing.ErrAlreadyExists, typing.ErrValidation}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := newAPIError(tt.statusCode, operationUpload, NFOType, "Some.Release-GRP", []byte(tt.body))
+			if apiErr.Message != tt.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tt.expectedMessage, apiErr.Message)
+			}
+
+			// Warnings wrap the error, errors.Is/As must still work
+			wrapped := fmt.Errorf("%s - %s: %w", "Some.Release-GRP", NFOType, apiErr)
+
+			var asErr *typing.APIError
+			if !errors.As(wrapped, &asErr) || asErr.StatusCode != tt.statusCode {
+				t.Errorf("Expected errors.As to find APIError with status %d", tt.statusCode)
+			}
+			for _, sentinel := range sentinels {
+				if got := errors.Is(wrapped, sentinel); got != (sentinel == tt.expectedKind) {
+					t.Errorf("errors.Is(%v) = %v", sentinel, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/files"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// filterFile runs opts.Filter on a MediaInfo or NFO file and applies a modification to entry and source.
+// It returns false if the file must not be uploaded.
+func filterFile(ctx context.Context, result *typing.ProcessResult, opts UploadOptions, entry *queueEntry, source *uploadSource) (reason string, ok bool) {
+	if opts.Filter == nil {
+		return "", true
+	}
+
+	data, err := readSource(*source)
+	if err != nil {
+		// Record the asset as failed, it must not vanish from the result
+		asset := typing.AssetResult{ReleaseName: entry.ReleaseName, AssetType: entry.AssetType, Status: typing.AssetFailed, Error: err.Error()}
+		result.Assets = append(result.Assets, asset)
+		result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: %w", entry.ReleaseName, entry.AssetType, err))
+		return "", false
+	}
+
+	payload := typing.Payload{ReleaseName: entry.ReleaseName, AssetType: entry.AssetType, OriginalFileName: entry.OriginalFileName, Data: data}
+	payload, reason, ok = applyFilter(ctx, result, opts, payload)
+	if ok {
+		// The filtered content is what gets queued as well
+		entry.OriginalFileName, entry.Data, entry.sourcePath = payload.OriginalFileName, payload.Data, ""
+		*source = bytesSource(payload.Data)
+	}
+	return reason, ok
+}
+
+// filterFileList runs opts.Filter on a file list and applies a modification to req.
+// It returns false if the file list must not be uploaded.
+func filterFileList(ctx context.Context, result *typing.ProcessResult, opts UploadOptions, req *files.FileListRequest) (reason string, ok bool) {
+	if opts.Filter == nil {
+		return "", true
+	}
+
+	fileList := typing.FileList(*req)
+	payload := typing.Payload{ReleaseName: req.ReleaseName, AssetType: FileListType, FileList: &fileList}
+	payload, reason, ok = applyFilter(ctx, result, opts, payload)
+	if ok {
+		releaseName := req.ReleaseName
+		*req = files.FileListRequest(*payload.FileList)
+		req.ReleaseName = releaseName
+	}
+	return reason, ok
+}
+
+// applyFilter asks opts.Filter about a payload. A vetoed payload is recorded as filtered asset.
+func applyFilter(ctx context.Context, result *typing.ProcessResult, opts UploadOptions, payload typing.Payload) (typing.Payload, string, bool) {
+	decision := opts.Filter(ctx, payload)
+	switch decision.Action {
+	case typing.PayloadSkip:
+		result.Assets = append(result.Assets, typing.AssetResult{ReleaseName: payload.ReleaseName, AssetType: payload.AssetType, Status: typing.AssetFiltered, Reason: decision.Reason})
+		reportProgress(ctx, typing.Event{Stage: typing.StageUpload, AssetType: payload.AssetType, Detail: "Filtered " + payload.AssetType + ": " + decision.Reason})
+		return payload, decision.Reason, false
+	case typing.PayloadModify:
+		// Fields left unset keep their original value
+		modified := decision.Payload
+		modified.ReleaseName, modified.AssetType = payload.ReleaseName, payload.AssetType
+		if modified.Data == nil {
+			modified.Data = payload.Data
+		}
+		if modified.OriginalFileName == "" {
+			modified.OriginalFileName = payload.OriginalFileName
+		}
+		if modified.FileList == nil {
+			modified.FileList = payload.FileList
+		}
+		return modified, decision.Reason, true
+	}
+	return payload, decision.Reason, true
+}
+
+// recordReason attaches the reason of the filter to the asset recorded last
+func recordReason(result *typing.ProcessResult, reason string) {
+	if reason != "" && len(result.Assets) > 0 {
+		result.Assets[len(result.Assets)-1].Reason = reason
+	}
+}
+
+func readSource(source uploadSource) ([]byte, error) {
+	r, err := source.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
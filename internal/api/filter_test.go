@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+func TestFilterModifyKeepsUnsetFields(t *testing.T) {
+	client := NewClient(Client{})
+	opts := UploadOptions{DryRun: true, Filter: func(ctx context.Context, payload typing.Payload) typing.PayloadDecision {
+		return typing.PayloadDecision{Action: typing.PayloadModify, Reason: "checked"}
+	}}
+
+	nfo := filepath.Join(t.TempDir(), "release.nfo")
+	if err := os.WriteFile(nfo, []byte("GRP PRESENTS"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result := client.uploadAssets(context.Background(), "Some.Release-GRP", nil, nfo, nil, opts)
+
+	if len(result.DryRun) != 1 || string(result.DryRun[0].Body) != "GRP PRESENTS" || result.DryRun[0].FileName != "release.nfo" {
+		t.Fatalf("Expected the original NFO, got %+v", result.DryRun)
+	}
+	if len(result.Assets) != 1 || result.Assets[0].Reason != "checked" {
+		t.Errorf("Expected the reason in the asset, got %+v", result.Assets)
+	}
+}
+
+func TestFilterUnreadableSource(t *testing.T) {
+	opts := UploadOptions{Filter: func(ctx context.Context, payload typing.Payload) typing.PayloadDecision {
+		return typing.PayloadDecision{Action: typing.PayloadKeep}
+	}}
+	entry := queueEntry{ReleaseName: "Some.Release-GRP", AssetType: NFOType}
+	var source uploadSource = &fileSource{path: filepath.Join(t.TempDir(), "missing.nfo")}
+
+	result := &typing.ProcessResult{}
+	if _, ok := filterFile(context.Background(), result, opts, &entry, &source); ok {
+		t.Fatal("Expected the unreadable NFO not to be uploaded")
+	}
+	if len(result.Assets) != 1 || result.Assets[0].Status != typing.AssetFailed || result.Assets[0].Error == "" {
+		t.Errorf("Expected a failed asset, got %+v", result.Assets)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Expected a warning, got %v", result.Warnings)
+	}
+}
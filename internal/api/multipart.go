@@ -0,0 +1,232 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"sync"
+)
+
+// uploadSource provides the content of an uploaded asset.
+// It can be opened repeatedly so failed requests can be retried.
+type uploadSource interface {
+	Open() (io.ReadCloser, error)
+	Size() int64 // -1 if unknown
+}
+
+// bytesSource serves an asset held in memory
+type bytesSource []byte
+
+func (b bytesSource) Open() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (b bytesSource) Size() int64 {
+	return int64(len(b))
+}
+
+// fileSource streams an asset from disk
+type fileSource struct {
+	path string
+	size int64
+}
+
+func newFileSource(path string) (*fileSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSource{path: path, size: info.Size()}, nil
+}
+
+func (f *fileSource) Open() (io.ReadCloser, error) {
+	return os.Open(f.path)
+}
+
+func (f *fileSource) Size() int64 {
+	return f.size
+}
+
+// multipartBody streams a multipart form with a single file through an io.Pipe
+// instead of building it in memory. If archivePath is set, the file content is
+// copied to archivePath while it is being sent. Archive failures never fail the body.
+type multipartBody struct {
+	fields      [][2]string // ordered name/value pairs
+	fileField   string
+	fileName    string
+	source      uploadSource
+	archivePath string
+	boundary    string
+
+	mu   sync.Mutex
+	done chan bodyResult // result of the writer goroutine of the latest Open
+}
+
+// bodyResult is the outcome of writing a body. A failing archive copy only sets
+// archiveErr, the body itself is still sent in full.
+type bodyResult struct {
+	err        error
+	archiveErr error
+}
+
+func newMultipartBody(fileField, fileName string, source uploadSource) *multipartBody {
+	return &multipartBody{
+		fileField: fileField,
+		fileName:  fileName,
+		source:    source,
+		boundary:  multipart.NewWriter(io.Discard).Boundary(),
+	}
+}
+
+// addField adds a form field, empty values are left out
+func (m *multipartBody) addField(name, value string) {
+	if value != "" {
+		m.fields = append(m.fields, [2]string{name, value})
+	}
+}
+
+func (m *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+// contentLength returns the exact size of the encoded body, or -1 if the source size is unknown
+func (m *multipartBody) contentLength() int64 {
+	if m.source.Size() < 0 {
+		return -1
+	}
+
+	// The encoding is deterministic for a fixed boundary, so the overhead
+	// can be measured by writing the form without the file content.
+	counter := &countingWriter{}
+	if err := m.writeForm(counter, nil); err != nil {
+		return -1
+	}
+	return counter.n + m.source.Size()
+}
+
+// Open returns a reader producing the encoded form. It matches the signature of http.Request.GetBody.
+func (m *multipartBody) Open() (io.ReadCloser, error) {
+	content, err := m.source.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan bodyResult, 1)
+	m.mu.Lock()
+	m.done = done
+	m.mu.Unlock()
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer content.Close()
+		result := m.write(pw, content)
+		pw.CloseWithError(result.err)
+		done <- result
+	}()
+
+	return pr, nil
+}
+
+// write encodes the form with the given content to w, teeing the content into the archive file
+func (m *multipartBody) write(w io.Writer, content io.Reader) bodyResult {
+	var archive *archiveWriter
+	var result bodyResult
+	if m.archivePath != "" {
+		file, err := os.Create(m.archivePath)
+		if err != nil {
+			result.archiveErr = fmt.Errorf("failed to create archive file: %w", err)
+		} else {
+			archive = &archiveWriter{file: file}
+		}
+	}
+
+	result.err = m.writeForm(w, func(part io.Writer) error {
+		dst := part
+		if archive != nil {
+			dst = io.MultiWriter(part, archive)
+		}
+		_, err := io.Copy(dst, content)
+		return err
+	})
+
+	if archive != nil {
+		result.archiveErr = archive.close()
+	}
+	return result
+}
+
+// wait blocks until the body produced by the latest Open has been fully written
+// and returns why the archive copy is incomplete, if it is
+func (m *multipartBody) wait() error {
+	m.mu.Lock()
+	done := m.done
+	m.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+	result := <-done
+	done <- result // keep the result for further calls
+	if result.err != nil {
+		return result.err
+	}
+	return result.archiveErr
+}
+
+// archiveWriter copies the content into the archive file. A write error is kept
+// and stops the copy without interrupting the upload.
+type archiveWriter struct {
+	file *os.File
+	err  error
+}
+
+func (a *archiveWriter) Write(p []byte) (int, error) {
+	if a.err == nil {
+		_, a.err = a.file.Write(p)
+	}
+	return len(p), nil
+}
+
+func (a *archiveWriter) close() error {
+	if err := a.file.Close(); a.err == nil {
+		a.err = err
+	}
+	return a.err
+}
+
+// writeForm encodes the form to w, writeFile fills in the file content
+func (m *multipartBody) writeForm(w io.Writer, writeFile func(part io.Writer) error) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+
+	for _, field := range m.fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+
+	part, err := writer.CreateFormFile(m.fileField, m.fileName)
+	if err != nil {
+		return fmt.Errorf("create form file: %w", err)
+	}
+	if writeFile != nil {
+		if err := writeFile(part); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+func TestUploadFileStreaming(t *testing.T) {
+	dir := t.TempDir()
+	nfoPath := filepath.Join(dir, "grp.nfo")
+	nfoContent := "Some NFO content\r\n"
+	if err := os.WriteFile(nfoPath, []byte(nfoContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	archiveDir := filepath.Join(dir, "archive")
+	if err := os.Mkdir(archiveDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt so the body has to be produced twice
+		if calls.Add(1) == 1 {
+			io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.URL.Path != "/api/releases/Some.Release-GRP/files" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.ContentLength <= 0 {
+			t.Errorf("Expected Content-Length to be set, got %d", r.ContentLength)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Failed to parse multipart form: %v", err)
+		}
+		for field, expected := range map[string]string{"FileType": NFOType, "OriginalFileName": "grp.nfo", "Category": "Movies", "FileHash": "abc"} {
+			if got := r.FormValue(field); got != expected {
+				t.Errorf("Expected %s=%q, got %q", field, expected, got)
+			}
+		}
+		file, header, err := r.FormFile("File")
+		if err != nil {
+			t.Fatalf("Missing file: %v", err)
+		}
+		content, _ := io.ReadAll(file)
+		if header.Filename != "grp.nfo" || string(content) != nfoContent {
+			t.Errorf("Unexpected file %q with content %q", header.Filename, content)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":42,"url":"https://crowdnfo.net/files/42"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Client{
+		BaseURL: server.URL,
+		Retry:   typing.RetryPolicy{InitialBackoff: time.Millisecond},
+	})
+	source, err := newFileSource(nfoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asset, archiveErr, err := client.uploadFile(context.Background(), "key", "Some.Release-GRP", NFOType, "grp.nfo", source, "abc", "Movies", archiveDir)
+	if err != nil || archiveErr != nil {
+		t.Fatalf("Unexpected error: %v, %v", err, archiveErr)
+	}
+	if asset.Attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", asset.Attempts)
+	}
+	if asset.StatusCode != http.StatusCreated || asset.ID != "42" || asset.URL != "https://crowdnfo.net/files/42" {
+		t.Errorf("Unexpected response fields: %+v", asset)
+	}
+	if asset.BytesSent <= int64(len(nfoContent)) || asset.Duration <= 0 {
+		t.Errorf("Expected bytes sent and duration, got %+v", asset)
+	}
+
+	archived, err := os.ReadFile(filepath.Join(archiveDir, "grp.nfo"))
+	if err != nil || string(archived) != nfoContent {
+		t.Errorf("Expected archived NFO, got %q (%v)", archived, err)
+	}
+	if parts, _ := filepath.Glob(filepath.Join(archiveDir, "*.part")); len(parts) != 0 {
+		t.Errorf("Expected partial archive file to be removed")
+	}
+}
+
+func TestUploadFileMissingArchiveDir(t *testing.T) {
+	mediaInfo := `{"media":{}}`
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		file, _, err := r.FormFile("File")
+		if err != nil {
+			t.Errorf("Unexpected form error: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if content, _ := io.ReadAll(file); string(content) != mediaInfo {
+			t.Errorf("Unexpected content %q", content)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	queueDir := t.TempDir()
+	client := NewClient(Client{BaseURL: server.URL, QueueDir: queueDir})
+	opts := UploadOptions{APIKey: "key", Category: "Movies", ArchiveDir: filepath.Join(t.TempDir(), "missing")}
+	result := client.uploadAssets(context.Background(), "Some.Release-GRP", []byte(mediaInfo), "", nil, opts)
+
+	// The upload succeeds, only the archive copy is reported as warning
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single upload, got %d", calls.Load())
+	}
+	if len(result.Assets) != 1 || result.Assets[0].Status != typing.AssetUploaded {
+		t.Errorf("Expected the asset to be uploaded, got %+v", result.Assets)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Error(), "failed to archive") {
+		t.Errorf("Expected an archive warning, got %v", result.Warnings)
+	}
+	if queued, _ := os.ReadDir(queueDir); len(queued) != 0 {
+		t.Errorf("Expected nothing to be queued, got %d entries", len(queued))
+	}
+}
+
+func TestUploadFileConcurrentArchive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	// Episodes sharing a general NFO archive it under the same name at once
+	archiveDir := t.TempDir()
+	client := NewClient(Client{BaseURL: server.URL})
+	var wg sync.WaitGroup
+	errs := make([]error, 6)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			releaseName := fmt.Sprintf("Some.Show.S01E%02d.1080p.WEB.h264-GRP", i+1)
+			_, archiveErr, err := client.uploadFile(context.Background(), "key", releaseName, NFOType, "grp.nfo", bytesSource("Some NFO content"), "", "TV", archiveDir)
+			errs[i] = errors.Join(err, archiveErr)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Upload %d failed: %v", i, err)
+		}
+	}
+	if archived, err := os.ReadFile(filepath.Join(archiveDir, "grp.nfo")); err != nil || string(archived) != "Some NFO content" {
+		t.Errorf("Expected archived NFO, got %q (%v)", archived, err)
+	}
+	if parts, _ := filepath.Glob(filepath.Join(archiveDir, "*.part")); len(parts) != 0 {
+		t.Errorf("Expected partial archive files to be removed, got %v", parts)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/version"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// Response headers used by CrowdNFO to announce client updates and deprecations
+const (
+	headerMinVersion    = "X-CrowdNFO-Min-Client-Version"
+	headerLatestVersion = "X-CrowdNFO-Latest-Client-Version"
+	headerNotice        = "X-CrowdNFO-Notice"
+	headerDeprecation   = "Deprecation"
+	headerSunset        = "Sunset"
+)
+
+// noticeState holds the latest server notice shared by all requests of a client
+type noticeState struct {
+	mu     sync.Mutex
+	notice *typing.ServerNotice
+}
+
+// checkUpdateHeaders inspects the response headers, records a changed notice and
+// reports it through the notice callback. It returns ErrUnsupportedVersion if the
+// server no longer accepts this client version.
+func (c *Client) checkUpdateHeaders(header http.Header) error {
+	notice := typing.ServerNotice{
+		ClientVersion: version.Version,
+		MinVersion:    header.Get(headerMinVersion),
+		LatestVersion: header.Get(headerLatestVersion),
+		Deprecation:   header.Get(headerDeprecation),
+		Sunset:        header.Get(headerSunset),
+		Message:       header.Get(headerNotice),
+	}
+	if notice.MinVersion != "" {
+		notice.Unsupported = version.Compare(version.Version, notice.MinVersion) < 0
+	}
+	if notice.LatestVersion != "" {
+		notice.UpdateAvailable = version.Compare(version.Version, notice.LatestVersion) < 0
+	}
+
+	if notice.UpdateAvailable || notice.Unsupported || notice.Deprecation != "" || notice.Sunset != "" || notice.Message != "" {
+		c.notices.mu.Lock()
+		changed := c.notices.notice == nil || *c.notices.notice != notice
+		if changed {
+			c.notices.notice = &notice
+		}
+		c.notices.mu.Unlock()
+
+		if changed && c.NoticeCB != nil {
+			c.NoticeCB(notice)
+		}
+	}
+
+	if notice.Unsupported {
+		return typing.ErrUnsupportedVersion
+	}
+	return nil
+}
+
+// Notice returns the latest notice announced by the server, or nil if there is none
+func (c *Client) Notice() *typing.ServerNotice {
+	c.notices.mu.Lock()
+	defer c.notices.mu.Unlock()
+
+	if c.notices.notice == nil {
+		return nil
+	}
+	notice := *c.notices.notice
+	return &notice
+}
+
+// Unsupported reports whether an earlier response declared this client version unsupported
+func (c *Client) Unsupported() bool {
+	notice := c.Notice()
+	return notice != nil && notice.Unsupported
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+func TestCheckUpdateHeaders(t *testing.T) {
+	var calls, notices atomic.Int32
+	var minVersion atomic.Value
+	minVersion.Store("0.0.0")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set(headerMinVersion, minVersion.Load().(string))
+		w.Header().Set(headerLatestVersion, "999.0.0")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Client{
+		BaseURL:  server.URL,
+		NoticeCB: func(notice typing.ServerNotice) { notices.Add(1) },
+	})
+
+	// An available update is reported once, requests keep working
+	for range 2 {
+		if _, err := client.GetRelease(context.Background(), "key", "Some.Release-GRP"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	notice := client.Notice()
+	if notice == nil || !notice.UpdateAvailable || notice.Unsupported {
+		t.Fatalf("Expected update notice, got %+v", notice)
+	}
+	if notices.Load() != 1 {
+		t.Errorf("Expected notice callback once, got %d", notices.Load())
+	}
+
+	// Once unsupported, the request fails and later ones are not sent at all
+	minVersion.Store("999.0.0")
+	if _, err := client.GetRelease(context.Background(), "key", "Some.Release-GRP"); !errors.Is(err, typing.ErrUnsupportedVersion) {
+		t.Fatalf("Expected ErrUnsupportedVersion, got %v", err)
+	}
+	if _, err := client.GetRelease(context.Background(), "key", "Some.Release-GRP"); !errors.Is(err, typing.ErrUnsupportedVersion) {
+		t.Fatalf("Expected ErrUnsupportedVersion, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 requests to reach the server, got %d", calls.Load())
+	}
+	if notices.Load() != 2 {
+		t.Errorf("Expected notice callback twice, got %d", notices.Load())
+	}
+}
+
+func TestUnsupportedUploadClosesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerMinVersion, "999.0.0")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Client{BaseURL: server.URL})
+	if _, err := client.GetRelease(context.Background(), "key", "Some.Release-GRP"); !errors.Is(err, typing.ErrUnsupportedVersion) {
+		t.Fatalf("Expected ErrUnsupportedVersion, got %v", err)
+	}
+
+	nfo := filepath.Join(t.TempDir(), "release.nfo")
+	if err := os.WriteFile(nfo, []byte("NFO"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := newFileSource(nfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := runtime.NumGoroutine()
+	for range 50 {
+		_, _, err := client.uploadFile(context.Background(), "key", "Some.Release-GRP", NFOType, "release.nfo", source, "", "Movies", "")
+		if !errors.Is(err, typing.ErrUnsupportedVersion) {
+			t.Fatalf("Expected ErrUnsupportedVersion, got %v", err)
+		}
+	}
+
+	// The writer goroutines exit once their pipe is closed
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before+5 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before+5 {
+		t.Errorf("Expected the upload goroutines to exit, went from %d to %d", before, after)
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"context"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+type progressKey struct{}
+
+// withProgress attaches the event callback of a release to ctx, so
+// low-level request code can report waits without extra parameters
+func withProgress(ctx context.Context, emit typing.EventCB) context.Context {
+	if emit == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey{}, emit)
+}
+
+// reportProgress passes the event to the callback attached to ctx, if any
+func reportProgress(ctx context.Context, event typing.Event) {
+	if emit, ok := ctx.Value(progressKey{}).(typing.EventCB); ok {
+		emit(event)
+	}
+}
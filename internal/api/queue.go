@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/files"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+const DefaultQueueMaxAge = 7 * 24 * time.Hour
+
+// queueEntry is a failed upload spooled to disk for a later retry
+type queueEntry struct {
+	ReleaseName      string                 `json:"releaseName"`
+	Category         string                 `json:"category"`
+	Hash             string                 `json:"hash,omitempty"`
+	AssetType        string                 `json:"assetType"`
+	OriginalFileName string                 `json:"originalFileName,omitempty"`
+	Data             []byte                 `json:"data,omitempty"`
+	FileList         *files.FileListRequest `json:"fileList,omitempty"`
+	ArchiveDir       string                 `json:"archiveDir,omitempty"`
+	QueuedAt         time.Time              `json:"queuedAt"`
+
+	sourcePath string // read into Data when the entry is queued
+}
+
+// queueable reports whether a failed upload is worth retrying later.
+// Only outages are queued, rejected uploads would be rejected again.
+func queueable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, typing.ErrUnsupportedVersion) || errors.Is(err, errInvalidTransport) {
+		return false
+	}
+	var apiErr *typing.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || errors.Is(apiErr, typing.ErrRateLimited)
+	}
+	return true
+}
+
+// rejected reports whether the server refused the upload itself, so replaying it is pointless.
+// Auth errors are not rejections, the same upload succeeds once the API key is fixed.
+func rejected(err error) bool {
+	var apiErr *typing.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 &&
+		!errors.Is(apiErr, typing.ErrUnauthorized) && !errors.Is(apiErr, typing.ErrRateLimited)
+}
+
+// recordUpload records the outcome of an upload, queueing it on disk if it failed temporarily
+func (c *Client) recordUpload(result *typing.ProcessResult, entry queueEntry, asset typing.AssetResult, err error) {
+	asset.Status = typing.AssetUploaded
+
+	if err != nil {
+		asset.Status = typing.AssetFailed
+		asset.Error = err.Error()
+		if c.QueueDir != "" && queueable(err) {
+			if queueErr := c.enqueue(entry); queueErr != nil {
+				result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: failed to queue upload: %w", entry.ReleaseName, entry.AssetType, queueErr))
+			} else {
+				asset.Status = typing.AssetQueued
+			}
+		}
+		result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: %w", entry.ReleaseName, entry.AssetType, err))
+	}
+
+	result.Assets = append(result.Assets, asset)
+}
+
+// recordArchiveError reports a failed archive copy of an upload as warning
+func recordArchiveError(result *typing.ProcessResult, entry queueEntry, err error) {
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: %w", entry.ReleaseName, entry.AssetType, err))
+	}
+}
+
+// enqueue writes the entry to the queue directory. An older entry for the same
+// release and asset type is replaced.
+func (c *Client) enqueue(entry queueEntry) error {
+	if entry.sourcePath != "" {
+		data, err := os.ReadFile(entry.sourcePath)
+		if err != nil {
+			return err
+		}
+		entry.Data = data
+	}
+	entry.QueuedAt = time.Now()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.QueueDir, 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated entry
+	path := filepath.Join(c.QueueDir, queueFileName(entry.ReleaseName, entry.AssetType))
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// FlushQueue replays all queued uploads. Uploaded, already existing, rejected and expired
+// entries are removed. All other entries stay queued, including those failing because of
+// cancellation, an unsupported client version, the transport configuration or the API key.
+func (c *Client) FlushQueue(ctx context.Context, apiKey string) (*typing.ProcessResult, error) {
+	result := &typing.ProcessResult{}
+	if c.QueueDir == "" {
+		return result, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(c.QueueDir, "*.json"))
+	if err != nil {
+		return result, err
+	}
+
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Errorf("failed to read queued upload %s: %w", filepath.Base(path), err))
+			continue
+		}
+
+		var entry queueEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Errorf("dropping corrupt queued upload %s: %w", filepath.Base(path), err))
+			os.Remove(path)
+			continue
+		}
+
+		if time.Since(entry.QueuedAt) > c.QueueMaxAge {
+			result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: dropping queued upload older than %s", entry.ReleaseName, entry.AssetType, c.QueueMaxAge))
+			os.Remove(path)
+			continue
+		}
+
+		// A file list entry without file list would fail on every flush
+		if entry.AssetType == FileListType && entry.FileList == nil {
+			result.Warnings = append(result.Warnings, fmt.Errorf("dropping corrupt queued upload %s: file list is missing", filepath.Base(path)))
+			os.Remove(path)
+			continue
+		}
+
+		var asset typing.AssetResult
+		var archiveErr error
+		switch entry.AssetType {
+		case FileListType:
+			asset, err = c.uploadFileList(ctx, apiKey, *entry.FileList)
+		default:
+			asset, archiveErr, err = c.uploadFile(ctx, apiKey, entry.ReleaseName, entry.AssetType, entry.OriginalFileName, bytesSource(entry.Data), entry.Hash, entry.Category, entry.ArchiveDir)
+		}
+		recordArchiveError(result, entry, archiveErr)
+
+		// An asset uploaded in the meantime is done as well
+		if errors.Is(err, typing.ErrAlreadyExists) {
+			asset.Status = typing.AssetSkipped
+			result.Assets = append(result.Assets, asset)
+			os.Remove(path)
+			continue
+		}
+
+		asset.Status = typing.AssetUploaded
+		if err != nil {
+			asset.Status = typing.AssetFailed
+			if queueable(err) {
+				asset.Status = typing.AssetQueued
+			}
+			asset.Error = err.Error()
+			result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: %w", entry.ReleaseName, entry.AssetType, err))
+		}
+		result.Assets = append(result.Assets, asset)
+
+		if err == nil || rejected(err) {
+			os.Remove(path)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+
+	return result, nil
+}
+
+// queueFileName derives a stable file name from release name and asset type,
+// so queueing the same asset twice keeps only the latest copy
+func queueFileName(releaseName, assetType string) string {
+	sum := sha256.Sum256([]byte(releaseName + "\x00" + assetType))
+	return strings.ToLower(assetType) + "-" + hex.EncodeToString(sum[:8]) + ".json"
+}
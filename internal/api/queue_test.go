@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/files"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+func TestQueueAndFlush(t *testing.T) {
+	var down atomic.Bool
+	var received atomic.Int32
+	down.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received.Add(1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	queueDir := filepath.Join(t.TempDir(), "queue")
+	client := NewClient(Client{
+		BaseURL:  server.URL,
+		Retry:    typing.RetryPolicy{MaxAttempts: 1},
+		QueueDir: queueDir,
+	})
+
+	entries := []files.FileListEntry{{FilePath: "grp.mkv", FileSizeBytes: 1}}
+	result := client.uploadAssets(context.Background(), "Some.Release-GRP", []byte(`{"media":{}}`), "", entries, UploadOptions{APIKey: "key", Category: "Movies", Hash: "abc"})
+
+	for _, asset := range result.Assets {
+		if asset.Status != typing.AssetQueued {
+			t.Errorf("Expected %s to be queued, got %s", asset.AssetType, asset.Status)
+		}
+	}
+	queued, _ := filepath.Glob(filepath.Join(queueDir, "*.json"))
+	if len(queued) != 2 {
+		t.Fatalf("Expected 2 queued uploads, got %d", len(queued))
+	}
+
+	// Queueing the same assets again replaces the existing entries
+	client.uploadAssets(context.Background(), "Some.Release-GRP", []byte(`{"media":{}}`), "", entries, UploadOptions{APIKey: "key", Category: "Movies", Hash: "abc"})
+	queued, _ = filepath.Glob(filepath.Join(queueDir, "*.json"))
+	if len(queued) != 2 {
+		t.Fatalf("Expected queued uploads to be deduplicated, got %d", len(queued))
+	}
+
+	down.Store(false)
+	result, err := client.FlushQueue(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Assets) != 2 || len(result.Warnings) != 0 {
+		t.Errorf("Expected 2 uploads without warnings, got %+v", result)
+	}
+	if received.Load() != 2 {
+		t.Errorf("Expected 2 uploads to reach the server, got %d", received.Load())
+	}
+	if remaining, _ := os.ReadDir(queueDir); len(remaining) != 0 {
+		t.Errorf("Expected empty queue after flush, %d entries left", len(remaining))
+	}
+}
+
+func TestFlushQueueDropsExpiredEntries(t *testing.T) {
+	client := NewClient(Client{BaseURL: "http://127.0.0.1:0", QueueDir: t.TempDir(), QueueMaxAge: 1})
+	if err := client.enqueue(queueEntry{ReleaseName: "Some.Release-GRP", AssetType: MediaInfoType, Data: []byte("{}")}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.FlushQueue(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 1 || len(result.Assets) != 0 {
+		t.Errorf("Expected expired entry to be dropped with a warning, got %+v", result)
+	}
+	if remaining, _ := os.ReadDir(client.QueueDir); len(remaining) != 0 {
+		t.Errorf("Expected empty queue, %d entries left", len(remaining))
+	}
+}
+
+func TestFlushQueueDropsFileListWithoutEntries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	client := NewClient(Client{BaseURL: server.URL, QueueDir: t.TempDir()})
+	if err := client.enqueue(queueEntry{ReleaseName: "Some.Release-GRP", AssetType: FileListType}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.FlushQueue(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 1 || len(result.Assets) != 0 || calls.Load() != 0 {
+		t.Errorf("Expected the corrupt entry to be dropped without a request, got %+v and %d requests", result, calls.Load())
+	}
+	if remaining, _ := os.ReadDir(client.QueueDir); len(remaining) != 0 {
+		t.Errorf("Expected empty queue, %d entries left", len(remaining))
+	}
+}
+
+func TestFlushQueueKeepsEntriesOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Cancel while the first entry is being replayed
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(Client{BaseURL: server.URL, QueueDir: t.TempDir()})
+	for _, assetType := range []string{MediaInfoType, NFOType} {
+		if err := client.enqueue(queueEntry{ReleaseName: "Some.Release-GRP", AssetType: assetType, Data: []byte("{}")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := client.FlushQueue(ctx, "key"); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if remaining, _ := filepath.Glob(filepath.Join(client.QueueDir, "*.json")); len(remaining) != 2 {
+		t.Errorf("Expected both entries to stay queued, %d left", len(remaining))
+	}
+}
+
+func TestFlushQueueKeepsEntriesOnAuthError(t *testing.T) {
+	for _, test := range []struct {
+		status int
+		kept   int
+	}{
+		{http.StatusUnauthorized, 1},
+		{http.StatusForbidden, 1},
+		{http.StatusBadRequest, 0},
+		{http.StatusUnprocessableEntity, 0},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+		}))
+
+		client := NewClient(Client{BaseURL: server.URL, Retry: typing.RetryPolicy{MaxAttempts: 1}, QueueDir: t.TempDir()})
+		if err := client.enqueue(queueEntry{ReleaseName: "Some.Release-GRP", AssetType: MediaInfoType, Data: []byte("{}")}); err != nil {
+			t.Fatal(err)
+		}
+		client.FlushQueue(context.Background(), "key")
+		server.Close()
+
+		if remaining, _ := filepath.Glob(filepath.Join(client.QueueDir, "*.json")); len(remaining) != test.kept {
+			t.Errorf("Status %d: expected %d queued entries, got %d", test.status, test.kept, len(remaining))
+		}
+	}
+}
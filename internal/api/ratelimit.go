@@ -0,0 +1,85 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket shared by all requests of a client.
+// It slows down when the server answers with 429 and recovers on success.
+type rateLimiter struct {
+	mu          sync.Mutex
+	limit       float64 // configured requests per second
+	rate        float64 // current requests per second, lowered after 429
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64, burst int) *rateLimiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		limit:  requestsPerSecond,
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller has to wait before using it
+func (l *rateLimiter) reserve() time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	if pause := l.pausedUntil.Sub(now); pause > delay {
+		delay = pause
+	}
+	return delay
+}
+
+// throttle pauses all requests for the given duration and halves the rate
+func (l *rateLimiter) throttle(pause time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(pause); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.rate = max(l.rate/2, l.limit/16)
+}
+
+// recover raises the rate back towards the configured limit after a successful request
+func (l *rateLimiter) recover() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rate = min(l.limit, l.rate+l.limit/10)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if newRateLimiter(0, 5) != nil {
+		t.Fatalf("Expected no limiter without a rate")
+	}
+
+	limiter := newRateLimiter(10, 2)
+
+	// The burst is available immediately, further requests are spaced by 1/rate
+	for i := range 2 {
+		if delay := limiter.reserve(); delay != 0 {
+			t.Errorf("Expected request %d within burst to pass, waited %v", i+1, delay)
+		}
+	}
+	if delay := limiter.reserve(); delay < 90*time.Millisecond || delay > 100*time.Millisecond {
+		t.Errorf("Expected ~100ms wait after burst, got %v", delay)
+	}
+
+	// A 429 pauses all requests and halves the rate
+	limiter.throttle(time.Second)
+	if delay := limiter.reserve(); delay < 900*time.Millisecond {
+		t.Errorf("Expected pause of ~1s after throttle, got %v", delay)
+	}
+	if limiter.rate != 5 {
+		t.Errorf("Expected rate 5 after throttle, got %v", limiter.rate)
+	}
+
+	for range 10 {
+		limiter.recover()
+	}
+	if limiter.rate != 10 {
+		t.Errorf("Expected rate to recover to 10, got %v", limiter.rate)
+	}
+}
+
+func TestRateLimitWaitReported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Client{BaseURL: server.URL, RateLimit: 20})
+	var waits []typing.Event
+	ctx := withProgress(context.Background(), func(event typing.Event) {
+		if event.Stage == typing.StageRateLimit {
+			waits = append(waits, event)
+		}
+	})
+
+	// The second request waits ~50ms, short waits are reported as well
+	for range 2 {
+		if _, err := client.GetRelease(ctx, "key", "Some.Release-GRP"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(waits) != 1 || !strings.HasSuffix(waits[0].Detail, "ms for rate limit") {
+		t.Errorf("Expected one rate limit event, got %+v", waits)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// ExistingAssets lists which asset types CrowdNFO already has for a release
+type ExistingAssets struct {
+	MediaInfo bool
+	NFO       bool
+	FileList  bool
+}
+
+// GetExistingAssets queries the releases endpoint for the asset types already present.
+// An unknown release is not an error, it simply has no assets yet.
+func (c *Client) GetExistingAssets(ctx context.Context, apiKey, releaseName string) (ExistingAssets, error) {
+	var existing ExistingAssets
+
+	release, err := c.GetRelease(ctx, apiKey, releaseName)
+	if errors.Is(err, typing.ErrNotFound) {
+		return existing, nil
+	}
+	if err != nil {
+		return existing, err
+	}
+
+	existing.MediaInfo = release.File(MediaInfoType) != nil
+	existing.NFO = release.File(NFOType) != nil
+	existing.FileList = release.HasFileList
+
+	return existing, nil
+}
+
+// GetRelease fetches a release with the list of its uploaded files
+func (c *Client) GetRelease(ctx context.Context, apiKey, releaseName string) (*typing.Release, error) {
+	body, err := c.get(ctx, apiKey, c.releasesURL(releaseName), operationQuery, "", releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	var release typing.Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+
+	return &release, nil
+}
+
+// DownloadFile downloads the content of the first file of the given type of a release
+func (c *Client) DownloadFile(ctx context.Context, apiKey, releaseName, fileType string) ([]byte, error) {
+	release, err := c.GetRelease(ctx, apiKey, releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	file := release.File(fileType)
+	if file == nil {
+		return nil, fmt.Errorf("%s has no %s: %w", releaseName, fileType, typing.ErrNotFound)
+	}
+
+	return c.get(ctx, apiKey, c.releasesURL(releaseName, "files", file.ID), operationDownload, fileType, releaseName)
+}
+
+// GetFileList fetches the file list of a release
+func (c *Client) GetFileList(ctx context.Context, apiKey, releaseName string) (*typing.FileList, error) {
+	body, err := c.get(ctx, apiKey, c.releasesURL(releaseName, "filelists"), operationDownload, FileListType, releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileList typing.FileList
+	if err := json.Unmarshal(body, &fileList); err != nil {
+		return nil, fmt.Errorf("failed to parse file list: %w", err)
+	}
+
+	return &fileList, nil
+}
+
+// get performs a GET request and returns the body of a successful response
+func (c *Client) get(ctx context.Context, apiKey, url, operation, assetType, releaseName string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create HTTP request: %w", err)
+	}
+
+	resp, cancel, _, err := c.do(req, apiKey, c.QueryTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp.StatusCode, operation, assetType, releaseName, body)
+	}
+
+	return body, nil
+}
+
+// Search fetches a single page of releases matching the query, pages start at 1
+func (c *Client) Search(ctx context.Context, apiKey, query, category string, page, pageSize int) (*typing.SearchPage, error) {
+	params := url.Values{}
+	params.Set("search", query)
+	if category != "" {
+		params.Set("category", category)
+	}
+	params.Set("page", strconv.Itoa(page))
+	params.Set("pageSize", strconv.Itoa(pageSize))
+
+	body, err := c.get(ctx, apiKey, fmt.Sprintf("%s/api/releases?%s", c.BaseURL, params.Encode()), operationQuery, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var searchPage typing.SearchPage
+	if err := json.Unmarshal(body, &searchPage); err != nil {
+		return nil, fmt.Errorf("failed to parse search results: %w", err)
+	}
+
+	return &searchPage, nil
+}
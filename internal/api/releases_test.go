@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetExistingAssets(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		expected    ExistingAssets
+		expectError bool
+	}{
+		{
+			name:     "Unknown release",
+			status:   404,
+			expected: ExistingAssets{},
+		},
+		{
+			name:     "NFO and file list present",
+			status:   200,
+			body:     `{"releaseName":"Some.Release-GRP","files":[{"fileType":"NFO"}],"hasFileList":true}`,
+			expected: ExistingAssets{NFO: true, FileList: true},
+		},
+		{
+			name:     "Complete release",
+			status:   200,
+			body:     `{"releaseName":"Some.Release-GRP","files":[{"fileType":"MediaInfo"},{"fileType":"NFO"}],"hasFileList":true}`,
+			expected: ExistingAssets{MediaInfo: true, NFO: true, FileList: true},
+		},
+		{
+			name:        "Unauthorized",
+			status:      401,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "GET" || r.URL.Path != "/api/releases/Some.Release-GRP" {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(Client{BaseURL: server.URL})
+			existing, err := client.GetExistingAssets(context.Background(), "key", "Some.Release-GRP")
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if existing != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, existing)
+			}
+		})
+	}
+}
+
+func TestReleasesURLEscaping(t *testing.T) {
+	tests := []struct {
+		releaseName string
+		segments    []string
+		expected    string
+	}{
+		{"Some.Release-GRP", []string{"files"}, "/api/releases/Some.Release-GRP/files"},
+		{"Some Release #1?-GRP", []string{"files"}, "/api/releases/Some%20Release%20%231%3F-GRP/files"},
+		{"100%.Pure-GRP", []string{"filelists"}, "/api/releases/100%25.Pure-GRP/filelists"},
+		{"Die.Brücke-GRP", nil, "/api/releases/Die.Br%C3%BCcke-GRP"},
+		{"A/B-GRP", []string{"files", "x/y"}, "/api/releases/A%2FB-GRP/files/x%2Fy"},
+	}
+
+	for _, tt := range tests {
+		var gotPath string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.EscapedPath()
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		client := NewClient(Client{BaseURL: server.URL})
+
+		url := client.releasesURL(tt.releaseName, tt.segments...)
+		if url != server.URL+tt.expected {
+			t.Errorf("Expected URL %s, got %s", server.URL+tt.expected, url)
+		}
+
+		// The escaped path must reach the server unchanged
+		client.get(context.Background(), "key", url, operationQuery, "", tt.releaseName)
+		if gotPath != tt.expected {
+			t.Errorf("Expected server to see %s, got %s", tt.expected, gotPath)
+		}
+		server.Close()
+	}
+}
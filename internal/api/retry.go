@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+const (
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 1 * time.Second
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+func normalizeRetryPolicy(policy typing.RetryPolicy) typing.RetryPolicy {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultMaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultMaxBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+	return policy
+}
+
+// shouldRetry decides whether a finished attempt is retried and how long to wait before the next one
+func (c *Client) shouldRetry(ctx context.Context, attempt int, resp *http.Response, err error) (bool, time.Duration) {
+	if attempt >= c.Retry.MaxAttempts || ctx.Err() != nil {
+		return false, 0
+	}
+
+	if err != nil {
+		// Per-attempt timeouts are retried, cancellation of the caller and broken configuration are not
+		if errors.Is(err, context.Canceled) || errors.Is(err, errInvalidTransport) {
+			return false, 0
+		}
+		return true, c.backoff(attempt)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return true, delay
+		}
+		return true, c.backoff(attempt)
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return true, c.backoff(attempt)
+	}
+
+	// Everything else, including 400 and 401, is final
+	return false, 0
+}
+
+// backoff returns the exponential backoff for the given attempt with jitter applied
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.Retry.InitialBackoff
+	for i := 1; i < attempt && delay < c.Retry.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.Retry.MaxBackoff {
+		delay = c.Retry.MaxBackoff
+	}
+
+	// Use between 50% and 100% of the delay so concurrent clients spread out
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until ctx is done
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drainAndClose discards the rest of the body so the connection can be reused
+func drainAndClose(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+}
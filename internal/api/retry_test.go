@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedDelay time.Duration
+		expectOK      bool
+	}{
+		{name: "Empty", value: "", expectOK: false},
+		{name: "Seconds", value: "5", expectedDelay: 5 * time.Second, expectOK: true},
+		{name: "Zero seconds", value: "0", expectedDelay: 0, expectOK: true},
+		{name: "Negative seconds", value: "-1", expectOK: false},
+		{name: "Date in the past", value: "Wed, 21 Oct 2015 07:28:00 GMT", expectedDelay: 0, expectOK: true},
+		{name: "Garbage", value: "soon", expectOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, ok := parseRetryAfter(tt.value)
+			if ok != tt.expectOK {
+				t.Fatalf("Expected ok=%v, got %v", tt.expectOK, ok)
+			}
+			if delay != tt.expectedDelay {
+				t.Errorf("Expected delay %v, got %v", tt.expectedDelay, delay)
+			}
+		})
+	}
+}
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		expectedAttempts int
+		expectedStatus   int
+	}{
+		{name: "Success on first attempt", statuses: []int{200}, expectedAttempts: 1, expectedStatus: 200},
+		{name: "Retry on 502", statuses: []int{502, 200}, expectedAttempts: 2, expectedStatus: 200},
+		{name: "Retry on 429", statuses: []int{429, 429, 201}, expectedAttempts: 3, expectedStatus: 201},
+		{name: "Give up after max attempts", statuses: []int{503, 503, 503, 200}, expectedAttempts: 3, expectedStatus: 503},
+		{name: "No retry on 400", statuses: []int{400, 200}, expectedAttempts: 1, expectedStatus: 400},
+		{name: "No retry on 401", statuses: []int{401, 200}, expectedAttempts: 1, expectedStatus: 401},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := int(calls.Add(1)) - 1
+				body := make([]byte, 4)
+				if n, _ := r.Body.Read(body); string(body[:n]) != "data" {
+					t.Errorf("Attempt %d received body %q", call+1, body[:n])
+				}
+				if tt.statuses[call] == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(tt.statuses[call])
+			}))
+			defer server.Close()
+
+			client := NewClient(Client{
+				BaseURL: server.URL,
+				Retry:   typing.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+			})
+
+			req, err := http.NewRequestWithContext(context.Background(), "POST", server.URL, strings.NewReader("data"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, cancel, attempts, err := client.do(req, "key", time.Second)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer cancel()
+			resp.Body.Close()
+
+			if attempts != tt.expectedAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.expectedAttempts, attempts)
+			}
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// TransportConfig holds the proxy and TLS settings of the default transport
+type TransportConfig struct {
+	ProxyURL           string // http, https, socks5 or socks5h URL, credentials as user:pass@
+	RootCAs            []byte // PEM encoded certificates trusted in addition to the system pool
+	ClientCertificates []tls.Certificate
+	PinnedHost         string   // host the pins apply to
+	PinnedKeys         []string // base64 encoded SHA-256 hashes of the SubjectPublicKeyInfo
+}
+
+// IsZero reports whether no setting differs from the default transport
+func (cfg TransportConfig) IsZero() bool {
+	return cfg.ProxyURL == "" && len(cfg.RootCAs) == 0 && len(cfg.ClientCertificates) == 0 && len(cfg.PinnedKeys) == 0
+}
+
+// NewTransport returns a copy of http.DefaultTransport with the config applied
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if len(cfg.RootCAs) == 0 && len(cfg.ClientCertificates) == 0 && len(cfg.PinnedKeys) == 0 {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(cfg.RootCAs) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(cfg.RootCAs) {
+			return nil, errors.New("no valid certificates found in root CAs")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	tlsConfig.Certificates = cfg.ClientCertificates
+
+	if len(cfg.PinnedKeys) > 0 {
+		pinnedHost := strings.ToLower(cfg.PinnedHost)
+		pins := slices.Clone(cfg.PinnedKeys)
+		// Regular verification still happens, the pins are checked on top of it
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			serverName := cs.ServerName
+			if serverName == "" && net.ParseIP(pinnedHost) != nil {
+				// No SNI is sent for IP addresses, so the server name is empty for every host
+				// dialed by IP, e.g. an HTTPS proxy. The certificate was already verified against
+				// the dialed address, it is only valid for the pinned address if that was dialed.
+				if len(cs.PeerCertificates) == 0 || cs.PeerCertificates[0].VerifyHostname(pinnedHost) != nil {
+					return nil
+				}
+				serverName = pinnedHost
+			}
+			if !strings.EqualFold(serverName, pinnedHost) {
+				return nil
+			}
+			for _, cert := range cs.PeerCertificates {
+				sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+				if slices.Contains(pins, base64.StdEncoding.EncodeToString(sum[:])) {
+					return nil
+				}
+			}
+			return fmt.Errorf("certificate of %s does not match any pinned public key", serverName)
+		}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// errInvalidTransport marks requests failing because of a broken transport configuration.
+// They are neither retried nor queued.
+var errInvalidTransport = errors.New("invalid transport configuration")
+
+// errorTransport fails every request with the error that occurred while building the transport
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
+
+// NewErrorTransport returns a RoundTripper failing every request with err
+func NewErrorTransport(err error) http.RoundTripper {
+	return errorTransport{err: fmt.Errorf("%w: %w", errInvalidTransport, err)}
+}
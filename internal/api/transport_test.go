@@ -0,0 +1,41 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPinnedIPIgnoresOtherAddresses(t *testing.T) {
+	// The test certificate is valid for 127.0.0.1 and ::1
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{server.Certificate()}}
+
+	tests := []struct {
+		name        string
+		pinnedHost  string
+		expectError bool
+	}{
+		{name: "Pinned address dialed", pinnedHost: "127.0.0.1", expectError: true},
+		{name: "Proxy dialed by IP", pinnedHost: "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := NewTransport(TransportConfig{PinnedHost: tt.pinnedHost, PinnedKeys: []string{"AAAA"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = transport.TLSClientConfig.VerifyConnection(state)
+			if tt.expectError && err == nil {
+				t.Error("Expected the mismatching pin to be rejected")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
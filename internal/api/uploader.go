@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/files"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// UploadFile uploads a MediaInfo or NFO file handed to an Uploader
+func (c *Client) UploadFile(ctx context.Context, apiKey, fileType string, file typing.FileUpload) error {
+	_, _, err := c.uploadFile(ctx, apiKey, file.ReleaseName, fileType, file.OriginalFileName, funcSource{file.Open, file.Size}, file.Hash, file.Category, "")
+	return err
+}
+
+// UploadFileList uploads a file list handed to an Uploader
+func (c *Client) UploadFileList(ctx context.Context, apiKey string, fileList typing.FileList) error {
+	_, err := c.uploadFileList(ctx, apiKey, files.FileListRequest(fileList))
+	return err
+}
+
+// funcSource adapts the content of a typing.FileUpload
+type funcSource struct {
+	open func() (io.ReadCloser, error)
+	size int64
+}
+
+func (f funcSource) Open() (io.ReadCloser, error) {
+	return f.open()
+}
+
+func (f funcSource) Size() int64 {
+	return f.size
+}
+
+// sendFile hands a MediaInfo or NFO file to the dry run, the custom uploader or CrowdNFO
+func (c *Client) sendFile(ctx context.Context, result *typing.ProcessResult, opts UploadOptions, entry queueEntry, source uploadSource) {
+	reportAsset(ctx, entry.AssetType)
+	defer reportAssetResult(ctx, result)
+
+	switch {
+	case opts.DryRun:
+		err := c.dryRunFile(result, entry.ReleaseName, entry.AssetType, entry.OriginalFileName, source, entry.Hash, entry.Category, opts)
+		recordDryRun(result, entry.ReleaseName, entry.AssetType, err)
+	case opts.Uploader != nil:
+		file := typing.FileUpload{
+			ReleaseName:      entry.ReleaseName,
+			Category:         entry.Category,
+			Hash:             entry.Hash,
+			FileName:         getFileName(entry.AssetType, entry.ReleaseName, entry.OriginalFileName),
+			OriginalFileName: entry.OriginalFileName,
+			Size:             source.Size(),
+			Open:             source.Open,
+		}
+		start := time.Now()
+		var err error
+		if entry.AssetType == NFOType {
+			err = opts.Uploader.UploadNFO(ctx, file)
+		} else {
+			err = opts.Uploader.UploadMediaInfo(ctx, file)
+		}
+		recordUploaderResult(result, entry, max(file.Size, 0), time.Since(start), err)
+	default:
+		asset, archiveErr, err := c.uploadFile(ctx, opts.APIKey, entry.ReleaseName, entry.AssetType, entry.OriginalFileName, source, entry.Hash, entry.Category, entry.ArchiveDir)
+		c.recordUpload(result, entry, asset, err)
+		recordArchiveError(result, entry, archiveErr)
+	}
+}
+
+// sendFileList hands a file list to the dry run, the custom uploader or CrowdNFO
+func (c *Client) sendFileList(ctx context.Context, result *typing.ProcessResult, opts UploadOptions, fileListRequest files.FileListRequest) {
+	entry := queueEntry{ReleaseName: fileListRequest.ReleaseName, Category: fileListRequest.Category, AssetType: FileListType, FileList: &fileListRequest}
+	reportAsset(ctx, FileListType)
+	defer reportAssetResult(ctx, result)
+
+	switch {
+	case opts.DryRun:
+		err := c.dryRunFileList(result, fileListRequest, opts)
+		recordDryRun(result, entry.ReleaseName, FileListType, err)
+	case opts.Uploader != nil:
+		start := time.Now()
+		err := opts.Uploader.UploadFileList(ctx, typing.FileList(fileListRequest))
+		recordUploaderResult(result, entry, 0, time.Since(start), err)
+	default:
+		asset, err := c.uploadFileList(ctx, opts.APIKey, fileListRequest)
+		c.recordUpload(result, entry, asset, err)
+	}
+}
+
+// recordUploaderResult records the outcome of an upload through a custom uploader.
+// Retrying and queueing are up to the uploader.
+func recordUploaderResult(result *typing.ProcessResult, entry queueEntry, size int64, duration time.Duration, err error) {
+	asset := typing.AssetResult{ReleaseName: entry.ReleaseName, AssetType: entry.AssetType, Status: typing.AssetUploaded, BytesSent: size, Duration: duration}
+	if err != nil {
+		asset.Status = typing.AssetFailed
+		asset.Error = err.Error()
+		result.Warnings = append(result.Warnings, fmt.Errorf("%s - %s: %w", entry.ReleaseName, entry.AssetType, err))
+	}
+	result.Assets = append(result.Assets, asset)
+}
+
+// reportAsset reports the start of an asset upload
+func reportAsset(ctx context.Context, assetType string) {
+	reportProgress(ctx, typing.Event{Stage: typing.StageUpload, AssetType: assetType, Detail: "Uploading " + assetType})
+}
+
+// reportAssetResult reports the outcome of the asset recorded last
+func reportAssetResult(ctx context.Context, result *typing.ProcessResult) {
+	if len(result.Assets) == 0 {
+		return
+	}
+	asset := result.Assets[len(result.Assets)-1]
+	event := typing.Event{Stage: typing.StageUpload, AssetType: asset.AssetType, BytesDone: asset.BytesSent, BytesTotal: asset.BytesSent}
+	switch asset.Status {
+	case typing.AssetUploaded:
+		event.Detail = "Uploaded " + asset.AssetType
+	case typing.AssetDryRun:
+		event.Detail = "Prepared " + asset.AssetType + " (dry run)"
+	case typing.AssetQueued:
+		event.Detail = "Queued " + asset.AssetType + " for a later retry"
+		event.Err = lastWarning(result)
+	default:
+		event.Detail = "Failed to upload " + asset.AssetType
+		event.Err = lastWarning(result)
+	}
+	reportProgress(ctx, event)
+}
+
+func lastWarning(result *typing.ProcessResult) error {
+	if len(result.Warnings) == 0 {
+		return nil
+	}
+	return result.Warnings[len(result.Warnings)-1]
+}
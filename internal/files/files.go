@@ -500,6 +500,23 @@ func ExtractEpisodeInfo(videoFile VideoFile, seasonPackName, generalNFO string)
 	return episodeInfo
 }
 
+// FindEpisodeNFO returns the NFO named like the video or the episode next to the video.
+// Without one, only episode E01 gets the general NFO, as in ExtractEpisodeInfo.
+func FindEpisodeNFO(videoFile VideoFile, releaseName, generalNFO string) string {
+	videoName := strings.TrimSuffix(videoFile.Name, filepath.Ext(videoFile.Name))
+	for _, name := range []string{videoName, releaseName} {
+		nfoPath := filepath.Join(videoFile.Dir, name+".nfo")
+		if _, err := os.Stat(nfoPath); err == nil {
+			return nfoPath
+		}
+	}
+
+	if extractEpisodeNumber(releaseName) == "E01" {
+		return generalNFO
+	}
+	return ""
+}
+
 // isRelatedFileByEpisode checks if a file is related based on episode number
 func isRelatedFileByEpisode(fileName, episodeNum string) bool {
 	// Extract episode number from the file name
@@ -1,5 +1,7 @@
 package files
 
+import "github.com/crowdnfo/crowdnfo-go/typing"
+
 // Structures for season pack processing
 type VideoFile struct {
 	Path string
@@ -8,10 +10,7 @@ type VideoFile struct {
 }
 
 // FileListEntry represents a single file in a file list
-type FileListEntry struct {
-	FilePath      string `json:"filePath"`
-	FileSizeBytes int64  `json:"fileSizeBytes"`
-}
+type FileListEntry = typing.FileListEntry
 
 // FileListRequest represents the JSON structure for file list API requests
 type FileListRequest struct {
@@ -47,7 +47,14 @@ func MergeProcessResults(a, b *typing.ProcessResult) *typing.ProcessResult {
 	if b == nil {
 		return a
 	}
+	notice := b.Notice
+	if notice == nil {
+		notice = a.Notice
+	}
 	return &typing.ProcessResult{
 		Warnings: append(a.Warnings, b.Warnings...),
+		Assets:   append(a.Assets, b.Assets...),
+		Notice:   notice,
+		DryRun:   append(a.DryRun, b.DryRun...),
 	}
 }
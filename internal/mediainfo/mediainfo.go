@@ -1,6 +1,7 @@
 package mediainfo
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"regexp"
@@ -10,14 +11,18 @@ import (
 
 const MinMediaInfoVersion = 2300
 
-func GenerateMediaInfoJSON(filePath, mediaInfoPath string) ([]byte, error) {
+// GenerateMediaInfoJSON runs MediaInfo on the given file, the process is killed when ctx is done
+func GenerateMediaInfoJSON(ctx context.Context, filePath, mediaInfoPath string) ([]byte, error) {
 	if mediaInfoPath == "" {
 		return nil, fmt.Errorf("MediaInfo is not available")
 	}
 
-	cmd := exec.Command(mediaInfoPath, "--Output=JSON", filePath)
+	cmd := exec.CommandContext(ctx, mediaInfoPath, "--Output=JSON", filePath)
 	output, err := cmd.Output()
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, fmt.Errorf("failed to run MediaInfo: %v", err)
 	}
 
@@ -25,14 +30,17 @@ func GenerateMediaInfoJSON(filePath, mediaInfoPath string) ([]byte, error) {
 }
 
 // CheckMediaInfoVersion checks if MediaInfo version is >= MinMediaInfoVersion
-func CheckMediaInfoVersion(mediaInfoPath string) error {
+func CheckMediaInfoVersion(ctx context.Context, mediaInfoPath string) error {
 	if mediaInfoPath == "" {
 		return fmt.Errorf("MediaInfo path is empty")
 	}
 
-	cmd := exec.Command(mediaInfoPath, "--Version")
+	cmd := exec.CommandContext(ctx, mediaInfoPath, "--Version")
 	output, err := cmd.Output()
 	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return fmt.Errorf("failed to get MediaInfo version: %v", err)
 	}
 
@@ -0,0 +1,70 @@
+// Package redact removes secrets such as the API key from strings and errors.
+package redact
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// Placeholder replaces every occurrence of a secret
+const Placeholder = "[REDACTED]"
+
+// String replaces every occurrence of secret in s
+func String(s, secret string) string {
+	if secret == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, secret, Placeholder)
+}
+
+// Error returns err with secret removed from its message. The result still
+// matches the same errors with errors.Is and errors.As. Messages of wrapped
+// *typing.APIError values are redacted in place.
+func Error(err error, secret string) error {
+	if err == nil || secret == "" || !strings.Contains(err.Error(), secret) {
+		return err
+	}
+
+	var apiErr *typing.APIError
+	if errors.As(err, &apiErr) {
+		apiErr.Message = String(apiErr.Message, secret)
+		if !strings.Contains(err.Error(), secret) {
+			return err
+		}
+	}
+
+	return &redactedError{err: err, secret: secret}
+}
+
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func (e *redactedError) Error() string {
+	return String(e.err.Error(), e.secret)
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// Result redacts the warnings, asset errors and dry run fields of a result in place
+func Result(result *typing.ProcessResult, secret string) {
+	if result == nil || secret == "" {
+		return
+	}
+	for i, warning := range result.Warnings {
+		result.Warnings[i] = Error(warning, secret)
+	}
+	for i := range result.Assets {
+		result.Assets[i].Error = String(result.Assets[i].Error, secret)
+	}
+	for i := range result.DryRun {
+		for name, value := range result.DryRun[i].Fields {
+			result.DryRun[i].Fields[name] = String(value, secret)
+		}
+	}
+}
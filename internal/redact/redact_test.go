@@ -0,0 +1,69 @@
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+func TestError(t *testing.T) {
+	const secret = "s3cr3t-key"
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"Plain error", fmt.Errorf("request with key %s failed", secret)},
+		{"Wrapped API error", fmt.Errorf("Some.Release-GRP - NFO: %w", &typing.APIError{StatusCode: 401, Operation: "upload", Message: "invalid key " + secret})},
+		{"Nested", fmt.Errorf("outer %s: %w", secret, fmt.Errorf("inner %s: %w", secret, typing.ErrRateLimited))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted := Error(tt.err, secret)
+			if strings.Contains(redacted.Error(), secret) {
+				t.Errorf("Expected secret to be redacted, got %q", redacted.Error())
+			}
+			if !strings.Contains(redacted.Error(), Placeholder) {
+				t.Errorf("Expected placeholder in %q", redacted.Error())
+			}
+			var target error
+			for _, sentinel := range []error{typing.ErrUnauthorized, typing.ErrRateLimited} {
+				if errors.Is(tt.err, sentinel) {
+					target = sentinel
+				}
+			}
+			if target != nil && !errors.Is(redacted, target) {
+				t.Errorf("Expected redacted error to still match %v", target)
+			}
+		})
+	}
+
+	var apiErr *typing.APIError
+	if !errors.As(Error(tests[1].err, secret), &apiErr) || strings.Contains(apiErr.Message, secret) {
+		t.Errorf("Expected the API error message to be redacted, got %+v", apiErr)
+	}
+}
+
+func TestErrorWithoutSecret(t *testing.T) {
+	err := errors.New("nothing to hide")
+	if Error(err, "") != err || Error(err, "key") != err || Error(nil, "key") != nil {
+		t.Errorf("Expected errors without the secret to be returned unchanged")
+	}
+}
+
+func TestResult(t *testing.T) {
+	result := &typing.ProcessResult{
+		Warnings: []error{errors.New("bad key abc123")},
+		Assets:   []typing.AssetResult{{Error: "abc123 rejected"}},
+		DryRun:   []typing.DryRunPayload{{Fields: map[string]string{"Category": "abc123"}}},
+	}
+	Result(result, "abc123")
+
+	if result.Warnings[0].Error() != "bad key "+Placeholder || result.Assets[0].Error != Placeholder+" rejected" || result.DryRun[0].Fields["Category"] != Placeholder {
+		t.Errorf("Expected result to be redacted, got %+v", result)
+	}
+}
@@ -0,0 +1,121 @@
+// Package releasename checks release names against the scene naming rules.
+package releasename
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// MaxLength is the longest release name allowed by the scene rules
+const MaxLength = 255
+
+// Validate checks the name against the scene naming rules: only ASCII letters, digits,
+// dots, underscores and dashes, at most MaxLength characters, no empty parts between
+// separators and a group suffix after the last dash. It returns all problems found.
+func Validate(name string) []typing.ReleaseNameProblem {
+	var problems []typing.ReleaseNameProblem
+	add := func(pos int, format string, args ...any) {
+		problems = append(problems, typing.ReleaseNameProblem{Pos: pos, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if name == "" {
+		add(-1, "name is empty")
+		return problems
+	}
+	if length := utf8.RuneCountInString(name); length > MaxLength {
+		add(-1, "name is %d characters long, at most %d are allowed", length, MaxLength)
+	}
+
+	for pos, r := range name {
+		if !allowed(r) {
+			add(pos, "character %q is not allowed", r)
+		}
+	}
+
+	if isSeparator(rune(name[0])) {
+		add(0, "name starts with %q", name[0])
+	}
+	if last := len(name) - 1; isSeparator(rune(name[last])) {
+		add(last, "name ends with %q", name[last])
+	}
+	for pos := 1; pos < len(name); pos++ {
+		if isSeparator(rune(name[pos])) && isSeparator(rune(name[pos-1])) {
+			add(pos, "separator %q follows %q", name[pos], name[pos-1])
+		}
+	}
+
+	dash := strings.LastIndexByte(name, '-')
+	if dash <= 0 {
+		add(-1, "group suffix \"-GROUP\" is missing")
+	} else if group := name[dash+1:]; strings.ContainsRune(group, '.') {
+		add(dash+1, "group %q contains a dot", group)
+	}
+
+	return problems
+}
+
+// replacements transliterates common non-ASCII characters
+var replacements = map[rune]string{
+	'ä': "ae", 'ö': "oe", 'ü': "ue", 'Ä': "Ae", 'Ö': "Oe", 'Ü': "Ue", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'å': "a", 'æ': "ae", 'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ø': "o", 'ù': "u", 'ú': "u", 'û': "u",
+	'ý': "y", 'ÿ': "y", 'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Å': "A", 'Æ': "Ae", 'Ç': "C",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I",
+	'Ñ': "N", 'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ø': "O", 'Ù': "U", 'Ú': "U", 'Û': "U", 'Ý': "Y",
+	'&': "and",
+}
+
+// Normalize rewrites the name to follow the naming rules as far as possible: non-ASCII
+// letters are transliterated, whitespace becomes a dot, other characters are dropped
+// and repeated or surrounding separators are removed. A missing group cannot be made up
+// and overlong names are not shortened, so the result may still fail Validate.
+func Normalize(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case allowed(r):
+			b.WriteRune(r)
+		case r == ' ' || r == '\t':
+			b.WriteByte('.')
+		case replacements[r] != "":
+			b.WriteString(replacements[r])
+		}
+	}
+
+	// Collapse runs of separators, keeping a dash since it marks the group
+	var out strings.Builder
+	normalized := b.String()
+	for i := 0; i < len(normalized); i++ {
+		c := normalized[i]
+		if !isSeparator(rune(c)) {
+			out.WriteByte(c)
+			continue
+		}
+		j := i
+		for j < len(normalized) && isSeparator(rune(normalized[j])) {
+			j++
+		}
+		run := normalized[i:j]
+		if strings.ContainsRune(run, '-') {
+			c = '-'
+		}
+		if out.Len() > 0 && j < len(normalized) {
+			out.WriteByte(c)
+		}
+		i = j - 1
+	}
+
+	return out.String()
+}
+
+func allowed(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || isSeparator(r)
+}
+
+func isSeparator(r rune) bool {
+	return r == '.' || r == '_' || r == '-'
+}
@@ -0,0 +1,69 @@
+package releasename
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		problems []string
+	}{
+		{"Some.Movie.2024.1080p.BluRay.x264-GRP", nil},
+		{"Some_Show.S01E02.720p.HDTV.x264-GRP_HD", nil},
+		{"", []string{"name is empty"}},
+		{"Some Movie.2024-GRP", []string{"character ' ' is not allowed at position 4"}},
+		{"Some.Movie#1?.2024-GRP", []string{"character '#' is not allowed at position 10", "character '?' is not allowed at position 12"}},
+		{"Sömé.Movie-GRP", []string{"character 'ö' is not allowed at position 1", "character 'é' is not allowed at position 4"}},
+		{".Some..Movie-", []string{"name starts with '.' at position 0", "name ends with '-' at position 12", "separator '.' follows '.' at position 6"}},
+		{"Some.Movie.2024", []string{"group suffix \"-GROUP\" is missing"}},
+		{"Some.Movie-GRP.INTERNAL", []string{"group \"GRP.INTERNAL\" contains a dot at position 11"}},
+	}
+
+	for _, test := range tests {
+		problems := Validate(test.name)
+		if len(problems) != len(test.problems) {
+			t.Errorf("Expected %d problems for %q, got %v", len(test.problems), test.name, problems)
+			continue
+		}
+		for i, problem := range problems {
+			if problem.String() != test.problems[i] {
+				t.Errorf("Expected problem %q for %q, got %q", test.problems[i], test.name, problem.String())
+			}
+		}
+	}
+}
+
+func TestValidateLength(t *testing.T) {
+	name := make([]byte, MaxLength)
+	for i := range name {
+		name[i] = 'a'
+	}
+	if problems := Validate(string(name[:MaxLength-4]) + "-GRP"); len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+	if problems := Validate(string(name) + "-GRP"); len(problems) != 1 {
+		t.Errorf("Expected a length problem, got %v", problems)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Some.Movie.2024.1080p.BluRay.x264-GRP", "Some.Movie.2024.1080p.BluRay.x264-GRP"},
+		{"Some Movie (2024) 1080p - GRP", "Some.Movie.2024.1080p-GRP"},
+		{"Die Brücke am Fluß 2024-GRP", "Die.Bruecke.am.Fluss.2024-GRP"},
+		{"..Tom & Jerry #1..-GRP", "Tom.and.Jerry.1-GRP"},
+		{"Café.Society.2016-GRP?", "Cafe.Society.2016-GRP"},
+	}
+
+	for _, test := range tests {
+		normalized := Normalize(test.name)
+		if normalized != test.expected {
+			t.Errorf("Expected %q for %q, got %q", test.expected, test.name, normalized)
+		}
+		if problems := Validate(normalized); len(problems) != 0 {
+			t.Errorf("Expected normalized %q to be valid, got %v", normalized, problems)
+		}
+	}
+}
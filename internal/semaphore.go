@@ -0,0 +1,35 @@
+package internal
+
+import "context"
+
+// Semaphore limits how many goroutines run a step at once.
+// A nil Semaphore does not limit anything.
+type Semaphore chan struct{}
+
+// NewSemaphore returns a semaphore admitting n holders, or nil for n <= 0
+func NewSemaphore(n int) Semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(Semaphore, n)
+}
+
+// Acquire blocks until a slot is free or ctx is done
+func (s Semaphore) Acquire(ctx context.Context) error {
+	if s == nil {
+		return ctx.Err()
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (s Semaphore) Release() {
+	if s != nil {
+		<-s
+	}
+}
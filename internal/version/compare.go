@@ -0,0 +1,46 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Compare compares two dotted version strings like "0.0.3" or "v1.2".
+// It returns -1 if a < b, 0 if a == b and 1 if a > b. Missing or
+// non-numeric parts count as 0, pre-release suffixes are ignored.
+func Compare(a, b string) int {
+	partsA := parse(a)
+	partsB := parse(b)
+
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func parse(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	var parts []int
+	for _, part := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(part)
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,24 @@
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"0.0.3", "0.0.3", 0},
+		{"0.0.3", "0.0.4", -1},
+		{"0.1.0", "0.0.9", 1},
+		{"v1.2", "1.2.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0-beta", "1.0.0", 0},
+		{"", "0.0.1", -1},
+	}
+
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.expected {
+			t.Errorf("Compare(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package crowdnfo
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/crowdnfo/crowdnfo-go/internal"
+	"github.com/crowdnfo/crowdnfo-go/internal/api"
+	"github.com/crowdnfo/crowdnfo-go/internal/files"
+	"github.com/crowdnfo/crowdnfo-go/internal/mediainfo"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// Step names a step of the processing pipeline, see Hook
+type Step string
+
+// Pipeline steps in the order they run. Detect and SelectFiles run once per release,
+// the other steps run for a single release or for every season pack episode.
+const (
+	StepDetect        Step = "detect"
+	StepSelectFiles   Step = "select-files"
+	StepProbe         Step = "probe"
+	StepFindNFO       Step = "find-nfo"
+	StepHash          Step = "hash"
+	StepBuildFileList Step = "build-file-list"
+	StepUpload        Step = "upload"
+)
+
+// StepFunc is a pipeline step or hook working on job
+type StepFunc func(ctx context.Context, job *Job) error
+
+// Hook runs custom code before or after a pipeline step, e.g. a virus scan after
+// StepSelectFiles or your own metadata extraction after StepProbe. An error stops the
+// release, for a season pack episode it is reported as warning and only stops that episode.
+// With Options.Concurrency above 1 the hooks of several episodes run at once, so they must
+// be safe for concurrent use.
+type Hook struct {
+	Step   Step
+	Before StepFunc // optional
+	After  StepFunc // optional
+}
+
+type pipelineStep struct {
+	step Step
+	run  StepFunc
+}
+
+// releaseSteps run once per release
+var releaseSteps = []pipelineStep{
+	{StepDetect, Detect},
+	{StepSelectFiles, SelectFiles},
+}
+
+// itemSteps run for a single release or for every season pack episode
+var itemSteps = []pipelineStep{
+	{StepProbe, Probe},
+	{StepFindNFO, FindNFO},
+	{StepHash, Hash},
+	{StepBuildFileList, BuildFileList},
+	{StepUpload, Upload},
+}
+
+// Job is the release model shared by the pipeline steps. Every step reads what the earlier
+// steps filled in and adds its own results, so hooks can inspect or replace them.
+// Season packs get one Job per episode in Episodes. Jobs are created by NewJob.
+type Job struct {
+	Options Options // options of this run, APIKey holds the resolved key
+
+	ReleaseName string // set by Detect
+	Category    string // set by Detect
+	SeasonPack  bool   // set by Detect
+
+	MediaFile    string // set by SelectFiles, used by Probe and Hash
+	FileListRoot string // set by SelectFiles for single releases
+	Episodes     []*Job // set by SelectFiles for season packs
+
+	MediaInfo []byte                 // MediaInfo JSON, set by Probe
+	NFOFile   string                 // set by FindNFO, or by SelectFiles for episodes
+	Hash      string                 // SHA-256 of MediaFile, set by Hash
+	FileList  []typing.FileListEntry // set by BuildFileList
+
+	Result *typing.ProcessResult // warnings and assets collected so far
+
+	env      *environment
+	progress reporter
+	episode  bool
+	existing *api.ExistingAssets // nil until checked
+}
+
+// NewJob resolves the API key, checks the MediaInfo version and returns a Job
+// ready for the pipeline. Process runs all steps, they can also be called one by one.
+func NewJob(ctx context.Context, opts Options) (*Job, error) {
+	env, err := newEnvironment(ctx, opts, 1)
+	if err != nil {
+		return nil, err
+	}
+	return newJob(env, opts), nil
+}
+
+func newJob(env *environment, opts Options) *Job {
+	opts.APIKey = env.apiKey
+	return &Job{
+		Options:  opts,
+		Result:   &typing.ProcessResult{},
+		env:      env,
+		progress: reporter{emit: env.emit},
+	}
+}
+
+// Process runs all pipeline steps together with the hooks in Options.Hooks.
+// When cancelled, the partial result collected so far is returned together with ctx.Err().
+func (j *Job) Process(ctx context.Context) (*typing.ProcessResult, error) {
+	return j.env.finish(j.process(ctx))
+}
+
+func (j *Job) process(ctx context.Context) (*typing.ProcessResult, error) {
+	for _, step := range releaseSteps {
+		if err := j.run(ctx, step); err != nil {
+			return nil, err
+		}
+	}
+
+	if j.SeasonPack {
+		return j.processEpisodes(ctx)
+	}
+
+	for _, step := range itemSteps {
+		if err := j.run(ctx, step); err != nil {
+			return j.Result, err
+		}
+	}
+	return j.Result, nil
+}
+
+// processEpisodes runs the item steps for all season pack episodes.
+// Up to Options.Concurrency episodes are processed at once, the results keep the episode order.
+func (j *Job) processEpisodes(ctx context.Context) (*typing.ProcessResult, error) {
+	client := j.env.client
+	workers := internal.NewSemaphore(max(j.Options.Concurrency, 1))
+	var wg sync.WaitGroup
+
+	for _, episode := range j.Episodes {
+		// No point in processing further episodes once the server refuses this client
+		if client.Unsupported() || workers.Acquire(ctx) != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer workers.Release()
+			episode.processEpisode(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for _, episode := range j.Episodes {
+		j.Result = internal.MergeProcessResults(j.Result, episode.Result)
+	}
+
+	if ctx.Err() != nil {
+		return j.Result, ctx.Err()
+	}
+	if client.Unsupported() {
+		return j.Result, ErrUnsupportedVersion
+	}
+
+	return j.Result, nil
+}
+
+// processEpisode runs the item steps for a single season pack episode. Failures are
+// reported as warnings, cancellation is left to the caller.
+func (j *Job) processEpisode(ctx context.Context) {
+	for _, step := range itemSteps {
+		if err := j.run(ctx, step); err != nil {
+			if ctx.Err() == nil {
+				j.Result.Warnings = append(j.Result.Warnings, fmt.Errorf("%s - %w", j.ReleaseName, err))
+			}
+			return
+		}
+	}
+}
+
+// run runs a step surrounded by its hooks
+func (j *Job) run(ctx context.Context, step pipelineStep) error {
+	for _, hook := range j.Options.Hooks {
+		if hook.Step == step.step && hook.Before != nil {
+			if err := hook.Before(ctx, j); err != nil {
+				return fmt.Errorf("before %s: %w", step.step, err)
+			}
+		}
+	}
+
+	if err := step.run(ctx, j); err != nil {
+		return err
+	}
+
+	for _, hook := range j.Options.Hooks {
+		if hook.Step == step.step && hook.After != nil {
+			if err := hook.After(ctx, j); err != nil {
+				return fmt.Errorf("after %s: %w", step.step, err)
+			}
+		}
+	}
+	return nil
+}
+
+// existingAssets asks CrowdNFO once which assets of the job it already has
+func (j *Job) existingAssets(ctx context.Context) api.ExistingAssets {
+	if j.existing == nil {
+		existing := checkExistingAssets(ctx, j.env.client, j.Options, j.ReleaseName, j.Result, j.progress)
+		j.existing = &existing
+	}
+	return *j.existing
+}
+
+// Detect determines the release name and category and whether the release is a season pack
+func Detect(ctx context.Context, job *Job) error {
+	releaseName := givenReleaseName(job.Options)
+	if releaseName == "" {
+		return fmt.Errorf("Could not determine release name from path: %s", job.Options.ReleasePath)
+	}
+
+	releaseName, err := checkReleaseName(releaseName, job.Options.ReleaseNameMode, job.progress)
+	if err != nil {
+		return err
+	}
+	job.ReleaseName = releaseName
+	job.progress.releaseName = releaseName
+
+	job.Category = getCategory(job.Options.Category, releaseName)
+	if job.Category == "" {
+		return fmt.Errorf("Invalid category: %s", job.Category)
+	}
+
+	// Check if this is a season pack
+	job.SeasonPack = len(job.Options.Episodes) > 0 || internal.IsSeasonPack(releaseName) || internal.IsSeasonPackFallback(job.Options.ReleasePath)
+	if job.SeasonPack {
+		job.progress.report(typing.StageStartup, "Detected Season Pack")
+	} else {
+		job.progress.report(typing.StageStartup, "Detected Single Release")
+	}
+	return nil
+}
+
+// givenReleaseName returns the release name as given in opts or on disk, before the release name mode applies
+func givenReleaseName(opts Options) string {
+	if opts.ReleaseName != "" {
+		return opts.ReleaseName
+	}
+	return files.GetBaseOrName(opts.ReleasePath)
+}
+
+// SelectFiles picks the media file of a single release or the episodes of a season pack
+func SelectFiles(ctx context.Context, job *Job) error {
+	if !job.SeasonPack {
+		mediaFile, err := findMediaFile(job.Options)
+		if err != nil {
+			return err
+		}
+		job.MediaFile = mediaFile
+		job.FileListRoot = job.Options.FileListRoot
+		if job.FileListRoot == "" {
+			job.FileListRoot = job.Options.ReleasePath
+		}
+		return nil
+	}
+
+	// Episodes are detected by comparing file and directory names with the given name, a normalized one would not match
+	episodes, err := seasonPackEpisodes(job.Options, givenReleaseName(job.Options), job.progress)
+	if err != nil {
+		return err
+	}
+	job.Episodes = make([]*Job, len(episodes))
+	for i, episode := range episodes {
+		// The episode names are the ones uploaded, so they follow the release name mode as well
+		releaseName, err := checkReleaseName(episode.ReleaseName, job.Options.ReleaseNameMode, job.progress)
+		if err != nil {
+			return err
+		}
+		job.Episodes[i] = &Job{
+			Options:     job.Options,
+			ReleaseName: releaseName,
+			Category:    job.Category,
+			MediaFile:   episode.VideoFile.Path,
+			NFOFile:     episode.NFOFile,
+			Result:      &typing.ProcessResult{},
+			env:         job.env,
+			progress:    job.progress.episode(releaseName, i, len(episodes)),
+			episode:     true,
+		}
+	}
+	return nil
+}
+
+// Probe generates the MediaInfo JSON of the media file. A failing MediaInfo is reported as warning.
+func Probe(ctx context.Context, job *Job) error {
+	existing := job.existingAssets(ctx)
+	skipMediaInfo, _ := skipExistingWork(job.Options, existing)
+
+	job.progress.report(typing.StageMetadata, "Generating MediaInfo")
+	mediaInfoPath := job.env.mediaInfoPath
+	// Generate MediaInfo JSON only for non-hash-only files
+	if job.MediaFile == "" || mediaInfoPath == "" || skipMediaInfo || files.IsHashOnlyFile(job.MediaFile) {
+		return nil
+	}
+
+	if err := job.env.limits.mediaInfo.Acquire(ctx); err != nil {
+		return err
+	}
+	mediaInfoJSON, err := mediainfo.GenerateMediaInfoJSON(ctx, job.MediaFile, mediaInfoPath)
+	job.env.limits.mediaInfo.Release()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		job.Result.Warnings = append(job.Result.Warnings, fmt.Errorf("%s - Failed to generate MediaInfo: %w", job.ReleaseName, err))
+		job.progress.event(typing.Event{Stage: typing.StageMetadata, Detail: "Failed to generate MediaInfo", Err: err})
+		return nil
+	}
+
+	job.MediaInfo = mediaInfoJSON
+	return nil
+}
+
+// FindNFO looks up the NFO of a single release unless Options.NFOFilePath is set.
+// Season pack episodes get theirs from SelectFiles.
+func FindNFO(ctx context.Context, job *Job) error {
+	if job.episode || job.NFOFile != "" {
+		return nil
+	}
+
+	job.progress.report(typing.StageMetadata, "Finding NFO File")
+	if job.Options.NFOFilePath != "" {
+		job.NFOFile = job.Options.NFOFilePath
+		return nil
+	}
+
+	nfoFile, err := files.FindNFOFile(job.Options.ReleasePath)
+	if err != nil {
+		job.Result.Warnings = append(job.Result.Warnings, fmt.Errorf("%s - No NFO File found", job.ReleaseName))
+		return nil
+	}
+	job.NFOFile = nfoFile
+	return nil
+}
+
+// Hash calculates the SHA-256 of the media file unless it exceeds Options.MaxHashFileSize
+func Hash(ctx context.Context, job *Job) error {
+	existing := job.existingAssets(ctx)
+	_, skipHash := skipExistingWork(job.Options, existing)
+
+	job.progress.report(typing.StageHashing, "Generating Hash")
+	// Calculate hash for any file found (media or ISO/IMG)
+	if job.MediaFile == "" || skipHash {
+		return nil
+	}
+
+	shouldHash, err := shouldCalculateHash(job.MediaFile, job.Options.MaxHashFileSize)
+	if err != nil {
+		return err
+	}
+	if !shouldHash {
+		job.Result.Warnings = append(job.Result.Warnings, fmt.Errorf("%s - Skip Hashing: File exceeds max_hash_file_size limit", job.ReleaseName))
+		return nil
+	}
+
+	if err := job.env.limits.hash.Acquire(ctx); err != nil {
+		return err
+	}
+	hash, err := calculateSHA256(ctx, job.MediaFile, hashProgressInterval(job.Options), job.progress)
+	job.env.limits.hash.Release()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		job.progress.event(typing.Event{Stage: typing.StageHashing, Detail: "Failed to generate Hash", Err: err})
+		return fmt.Errorf("Failed to generate Hash: %w", err)
+	}
+
+	job.Hash = hash
+	return nil
+}
+
+// BuildFileList lists the files of a single release below FileListRoot, or the files belonging
+// to a season pack episode. A failure is reported as warning and no file list is uploaded.
+func BuildFileList(ctx context.Context, job *Job) error {
+	var entries []typing.FileListEntry
+	var err error
+	if job.episode {
+		entries, err = files.CreateEpisodeFileList(files.EpisodeInfo{
+			VideoFile: files.VideoFile{
+				Path: job.MediaFile,
+				Dir:  filepath.Dir(job.MediaFile),
+				Name: filepath.Base(job.MediaFile),
+			},
+			ReleaseName: job.ReleaseName,
+			NFOFile:     job.NFOFile,
+		})
+	} else {
+		entries, err = files.CreateFileList(job.FileListRoot, job.ReleaseName)
+	}
+	if err != nil {
+		job.Result.Warnings = append(job.Result.Warnings, fmt.Errorf("%s - Failed to create File List: %w", job.ReleaseName, err))
+		job.FileList = nil
+		return nil
+	}
+
+	job.FileList = entries
+	return nil
+}
+
+// Upload sends the MediaInfo, NFO and file list to CrowdNFO or Options.Uploader.
+// Failed uploads are reported as warnings in Result.
+func Upload(ctx context.Context, job *Job) error {
+	existing := job.existingAssets(ctx)
+
+	if err := job.env.limits.upload.Acquire(ctx); err != nil {
+		return err
+	}
+	defer job.env.limits.upload.Release()
+
+	job.progress.report(typing.StageUpload, "Uploading")
+	uploadResult := job.env.client.UploadAssets(ctx, job.ReleaseName, job.MediaInfo, job.NFOFile, job.FileList, uploadOptions(job.Options, job.Category, job.Hash, existing, job.progress))
+	job.Result = internal.MergeProcessResults(job.Result, uploadResult)
+
+	return ctx.Err()
+}
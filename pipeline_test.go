@@ -0,0 +1,137 @@
+package crowdnfo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/crowdnfo/crowdnfo-go"
+	"github.com/crowdnfo/crowdnfo-go/crowdnfotest"
+)
+
+func TestProcessReleaseHooks(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, _ := writeMovieRelease(t)
+
+	var calls []string
+	record := func(name string) crowdnfo.StepFunc {
+		return func(ctx context.Context, job *crowdnfo.Job) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	opts := newTestOptions(t, server, releasePath)
+	opts.Hooks = []crowdnfo.Hook{
+		{Step: crowdnfo.StepSelectFiles, After: func(ctx context.Context, job *crowdnfo.Job) error {
+			if job.MediaFile == "" {
+				t.Error("Expected the media file to be selected")
+			}
+			calls = append(calls, "scan")
+			return nil
+		}},
+		{Step: crowdnfo.StepProbe, After: func(ctx context.Context, job *crowdnfo.Job) error {
+			job.MediaInfo = []byte(`{"custom":true}`)
+			return nil
+		}},
+		{Step: crowdnfo.StepUpload, Before: record("before upload"), After: record("after upload")},
+	}
+
+	if _, err := crowdnfo.ProcessRelease(opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"scan", "before upload", "after upload"}; !slices.Equal(calls, want) {
+		t.Errorf("Expected hooks %v, got %v", want, calls)
+	}
+	uploads := server.Uploads()
+	if len(uploads) != 2 || string(uploads[0].Content) != `{"custom":true}` {
+		t.Errorf("Expected the MediaInfo of the hook to be uploaded, got %+v", uploads)
+	}
+
+	// A failing hook stops the release before anything is uploaded
+	errInfected := errors.New("infected")
+	opts.Hooks = []crowdnfo.Hook{{Step: crowdnfo.StepHash, Before: func(ctx context.Context, job *crowdnfo.Job) error {
+		return errInfected
+	}}}
+	if _, err := crowdnfo.ProcessRelease(opts); !errors.Is(err, errInfected) {
+		t.Errorf("Expected the hook error, got %v", err)
+	}
+	if len(server.Uploads()) != 2 {
+		t.Errorf("Expected no further uploads, got %d", len(server.Uploads()))
+	}
+}
+
+func TestProcessSeasonPackHookError(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+
+	dir := t.TempDir()
+	var episodes []crowdnfo.Episode
+	for i := 1; i <= 2; i++ {
+		name := fmt.Sprintf("Some.Show.S01E%02d.1080p.WEB.h264-GRP", i)
+		path := filepath.Join(dir, name+".mkv")
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		episodes = append(episodes, crowdnfo.Episode{ReleaseName: name, MediaFilePath: path})
+	}
+
+	opts := newTestOptions(t, server, dir)
+	opts.ReleaseName = "Some.Show.S01.1080p.WEB.h264-GRP"
+	opts.Episodes = episodes
+	opts.Hooks = []crowdnfo.Hook{{Step: crowdnfo.StepUpload, Before: func(ctx context.Context, job *crowdnfo.Job) error {
+		if job.ReleaseName == episodes[0].ReleaseName {
+			return errors.New("rejected")
+		}
+		return nil
+	}}}
+
+	result, err := crowdnfo.ProcessRelease(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Error(), episodes[0].ReleaseName+" - before upload: rejected") {
+		t.Errorf("Expected a warning for the first episode, got %v", result.Warnings)
+	}
+	for _, upload := range server.Uploads() {
+		if upload.ReleaseName != episodes[1].ReleaseName {
+			t.Errorf("Unexpected upload of %s", upload.ReleaseName)
+		}
+	}
+}
+
+func TestJobSteps(t *testing.T) {
+	server := crowdnfotest.NewServer("key")
+	defer server.Close()
+	releasePath, hash := writeMovieRelease(t)
+
+	ctx := context.Background()
+	job, err := crowdnfo.NewJob(ctx, newTestOptions(t, server, releasePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []crowdnfo.StepFunc{crowdnfo.Detect, crowdnfo.SelectFiles, crowdnfo.FindNFO, crowdnfo.Hash, crowdnfo.BuildFileList} {
+		if err := step(ctx, job); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if job.ReleaseName != movieName || job.Category != "Movies" || job.SeasonPack {
+		t.Errorf("Unexpected detection: %q %q %v", job.ReleaseName, job.Category, job.SeasonPack)
+	}
+	if filepath.Base(job.MediaFile) != "grp-somemovie.mkv" || filepath.Base(job.NFOFile) != "grp-somemovie.nfo" {
+		t.Errorf("Unexpected files: %s %s", job.MediaFile, job.NFOFile)
+	}
+	if job.Hash != hash || len(job.FileList) != 2 || job.MediaInfo != nil {
+		t.Errorf("Unexpected hash %s, file list %v or MediaInfo", job.Hash, job.FileList)
+	}
+	if server.Requests() != 0 {
+		t.Errorf("Expected no requests before Upload, got %d", server.Requests())
+	}
+}
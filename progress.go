@@ -0,0 +1,139 @@
+package crowdnfo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/redact"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// newEmitter combines the progress callbacks and channel of the options into one event callback.
+// Events are delivered one at a time with Elapsed set and the API key redacted. Sending to
+// opts.Events blocks until the event is received or ctx is done.
+func newEmitter(ctx context.Context, opts Options, apiKey string) typing.EventCB {
+	var sinks []typing.EventCB
+	if opts.ProgressCB != nil {
+		sinks = append(sinks, typing.ProgressEvents(opts.ProgressCB))
+	}
+	if opts.EventCB != nil {
+		sinks = append(sinks, opts.EventCB)
+	}
+	if opts.Events != nil {
+		events := opts.Events
+		sinks = append(sinks, func(event typing.Event) {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+			}
+		})
+	}
+	if len(sinks) == 0 {
+		return func(event typing.Event) {}
+	}
+
+	start := time.Now()
+	var mu sync.Mutex
+	return func(event typing.Event) {
+		event.Elapsed = time.Since(start)
+		event.Detail = redact.String(event.Detail, apiKey)
+		event.Err = redact.Error(event.Err, apiKey)
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, sink := range sinks {
+			sink(event)
+		}
+	}
+}
+
+// reporter emits the events of a release or season pack episode
+type reporter struct {
+	emit         typing.EventCB
+	releaseName  string
+	episodeName  string
+	episodeIndex int
+	episodeTotal int
+}
+
+// episode returns a reporter for the i-th of total episodes
+func (r reporter) episode(episodeName string, i, total int) reporter {
+	r.episodeName, r.episodeIndex, r.episodeTotal = episodeName, i+1, total
+	return r
+}
+
+// name returns the name of the release or episode the reporter belongs to
+func (r reporter) name() string {
+	if r.episodeName != "" {
+		return r.episodeName
+	}
+	return r.releaseName
+}
+
+// report emits an event with the given stage and detail
+func (r reporter) report(stage typing.Stage, detail string) {
+	r.event(typing.Event{Stage: stage, Detail: detail})
+}
+
+// event fills in the release and episode and emits the event
+func (r reporter) event(event typing.Event) {
+	event.ReleaseName = r.releaseName
+	event.EpisodeName = r.episodeName
+	event.EpisodeIndex = r.episodeIndex
+	event.EpisodeTotal = r.episodeTotal
+	r.emit(event)
+}
+
+// hashProgress reports the progress of hashing a file
+func (r reporter) hashProgress(done, total int64, elapsed time.Duration) {
+	event := typing.Event{Stage: typing.StageHashing, BytesDone: done, BytesTotal: total}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		event.Throughput = float64(done) / seconds
+	}
+	if event.Throughput > 0 && total > done {
+		event.ETA = time.Duration(float64(total-done) / event.Throughput * float64(time.Second)).Round(time.Second)
+	}
+
+	const mb = 1024 * 1024
+	percent := 100.0
+	if total > 0 {
+		percent = float64(done) / float64(total) * 100
+	}
+	event.Detail = fmt.Sprintf("Hashing %.0f%% (%.0f of %.0f MB, %.1f MB/s, ETA %s)", percent, float64(done)/mb, float64(total)/mb, event.Throughput/mb, event.ETA)
+	r.event(event)
+}
+
+// progressReader calls report with the bytes read so far at most once per interval
+type progressReader struct {
+	r        io.Reader
+	total    int64
+	interval time.Duration
+	report   func(done, total int64, elapsed time.Duration)
+
+	done  int64
+	start time.Time
+	last  time.Time
+}
+
+func newProgressReader(r io.Reader, total int64, interval time.Duration, report func(done, total int64, elapsed time.Duration)) *progressReader {
+	now := time.Now()
+	return &progressReader{r: r, total: total, interval: interval, report: report, start: now, last: now}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	if now := time.Now(); now.Sub(p.last) >= p.interval {
+		p.last = now
+		p.report(p.done, p.total, now.Sub(p.start))
+	}
+	return n, err
+}
+
+// finish reports the final state once everything has been read
+func (p *progressReader) finish() {
+	p.report(p.done, p.total, time.Since(p.start))
+}
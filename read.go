@@ -0,0 +1,37 @@
+package crowdnfo
+
+import (
+	"context"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/api"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// GetRelease fetches a release and the list of its uploaded files from CrowdNFO.
+// Unknown releases return an error matching ErrNotFound.
+func (c *Client) GetRelease(ctx context.Context, releaseName string) (*typing.Release, error) {
+	return withAPIKey(ctx, c, func(apiKey string) (*typing.Release, error) {
+		return c.apiClient().GetRelease(ctx, apiKey, releaseName)
+	})
+}
+
+// DownloadNFO downloads the NFO of a release
+func (c *Client) DownloadNFO(ctx context.Context, releaseName string) ([]byte, error) {
+	return withAPIKey(ctx, c, func(apiKey string) ([]byte, error) {
+		return c.apiClient().DownloadFile(ctx, apiKey, releaseName, api.NFOType)
+	})
+}
+
+// DownloadMediaInfo downloads the MediaInfo JSON of a release
+func (c *Client) DownloadMediaInfo(ctx context.Context, releaseName string) ([]byte, error) {
+	return withAPIKey(ctx, c, func(apiKey string) ([]byte, error) {
+		return c.apiClient().DownloadFile(ctx, apiKey, releaseName, api.MediaInfoType)
+	})
+}
+
+// GetFileList fetches the file list of a release
+func (c *Client) GetFileList(ctx context.Context, releaseName string) (*typing.FileList, error) {
+	return withAPIKey(ctx, c, func(apiKey string) (*typing.FileList, error) {
+		return c.apiClient().GetFileList(ctx, apiKey, releaseName)
+	})
+}
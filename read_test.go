@@ -0,0 +1,79 @@
+package crowdnfo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newFakeReadServer serves a single release with an NFO and a file list
+func newFakeReadServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/releases/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.PathValue("name") != "Some.Movie.2024.1080p.BluRay.x264-GRP" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"releaseName":"Some.Movie.2024.1080p.BluRay.x264-GRP","category":"Movies","files":[{"id":"17","fileType":"NFO","originalFileName":"grp.nfo"}],"hasFileList":true}`))
+	})
+	mux.HandleFunc("GET /api/releases/{name}/files/17", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("NFO CONTENT"))
+	})
+	mux.HandleFunc("GET /api/releases/{name}/filelists", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"releaseName":"Some.Movie.2024.1080p.BluRay.x264-GRP","category":"Movies","entries":[{"filePath":"grp.mkv","fileSizeBytes":1234},{"filePath":"grp.nfo","fileSizeBytes":12}]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientRead(t *testing.T) {
+	server := newFakeReadServer(t)
+	client := &Client{BaseURL: server.URL, APIKey: "key"}
+	ctx := context.Background()
+	name := "Some.Movie.2024.1080p.BluRay.x264-GRP"
+
+	release, err := client.GetRelease(ctx, name)
+	if err != nil {
+		t.Fatalf("GetRelease: %v", err)
+	}
+	if release.Category != "Movies" || len(release.Files) != 1 || !release.HasFileList {
+		t.Errorf("Unexpected release: %+v", release)
+	}
+
+	nfo, err := client.DownloadNFO(ctx, name)
+	if err != nil {
+		t.Fatalf("DownloadNFO: %v", err)
+	}
+	if string(nfo) != "NFO CONTENT" {
+		t.Errorf("Unexpected NFO: %q", nfo)
+	}
+
+	if _, err := client.DownloadMediaInfo(ctx, name); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for missing MediaInfo, got %v", err)
+	}
+
+	fileList, err := client.GetFileList(ctx, name)
+	if err != nil {
+		t.Fatalf("GetFileList: %v", err)
+	}
+	if len(fileList.Entries) != 2 || fileList.Entries[0].FilePath != "grp.mkv" || fileList.Entries[0].FileSizeBytes != 1234 {
+		t.Errorf("Unexpected file list: %+v", fileList)
+	}
+
+	if _, err := client.GetRelease(ctx, "Unknown.Release-GRP"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown release, got %v", err)
+	}
+
+	unauthorized := &Client{BaseURL: server.URL, APIKey: "wrong"}
+	if _, err := unauthorized.GetRelease(ctx, name); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+}
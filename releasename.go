@@ -0,0 +1,48 @@
+package crowdnfo
+
+import (
+	"github.com/crowdnfo/crowdnfo-go/internal/releasename"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// ReleaseNameMode selects how ProcessRelease handles release names violating the scene naming rules
+type ReleaseNameMode int
+
+const (
+	ReleaseNameAccept    ReleaseNameMode = iota // use the name as it is, the default
+	ReleaseNameReject                           // fail with a *ReleaseNameError before any work starts
+	ReleaseNameNormalize                        // use NormalizeReleaseName, fail if the result is still invalid
+)
+
+// ValidateReleaseName checks a release name against the scene naming rules: only ASCII
+// letters, digits, dots, underscores and dashes, at most 255 characters, no empty parts
+// between separators and a "-GROUP" suffix. It returns a *ReleaseNameError listing every
+// problem found, or nil if the name is valid.
+func ValidateReleaseName(name string) error {
+	if problems := releasename.Validate(name); len(problems) > 0 {
+		return &ReleaseNameError{ReleaseName: name, Problems: problems}
+	}
+	return nil
+}
+
+// NormalizeReleaseName rewrites a release name to follow the naming rules as far as possible,
+// e.g. "Die Brücke (2024) - GRP" becomes "Die.Bruecke.2024-GRP". A missing group is not added.
+func NormalizeReleaseName(name string) string {
+	return releasename.Normalize(name)
+}
+
+// checkReleaseName applies the release name mode, returning the name to use
+func checkReleaseName(name string, mode ReleaseNameMode, progress reporter) (string, error) {
+	switch mode {
+	case ReleaseNameReject:
+		return name, ValidateReleaseName(name)
+	case ReleaseNameNormalize:
+		normalized := NormalizeReleaseName(name)
+		if normalized != name {
+			progress.releaseName = normalized
+			progress.report(typing.StageStartup, "Normalized release name "+name)
+		}
+		return normalized, ValidateReleaseName(normalized)
+	}
+	return name, nil
+}
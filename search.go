@@ -0,0 +1,79 @@
+package crowdnfo
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+const defaultSearchPageSize = 50
+
+// SearchQuery holds the parameters of a release search.
+type SearchQuery struct {
+	Query    string // full or partial release name
+	Category string // optional, one of the CrowdNFO categories
+	PageSize int    // optional, defaults to 50
+}
+
+// validate checks the query and fills in defaults
+func (q *SearchQuery) validate() error {
+	if q.Query == "" {
+		return fmt.Errorf("Search query is required: %w", ErrValidation)
+	}
+	if q.Category != "" && !isValidCategory(q.Category) {
+		return fmt.Errorf("Invalid category: %s: %w", q.Category, ErrValidation)
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = defaultSearchPageSize
+	}
+	return nil
+}
+
+// SearchPage fetches a single page of search results, pages start at 1.
+func (c *Client) SearchPage(ctx context.Context, query SearchQuery, page int) (*typing.SearchPage, error) {
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	return withAPIKey(ctx, c, func(apiKey string) (*typing.SearchPage, error) {
+		return c.apiClient().Search(ctx, apiKey, query.Query, query.Category, page, query.PageSize)
+	})
+}
+
+// Search iterates over all releases matching the query, fetching further pages until a page
+// is empty or the total count announced by the server is reached.
+// Iteration stops after the first error, which is yielded with an empty summary.
+func (c *Client) Search(ctx context.Context, query SearchQuery) iter.Seq2[typing.ReleaseSummary, error] {
+	return func(yield func(typing.ReleaseSummary, error) bool) {
+		if err := query.validate(); err != nil {
+			yield(typing.ReleaseSummary{}, err)
+			return
+		}
+
+		seen := 0
+		for page := 1; ; page++ {
+			result, err := c.SearchPage(ctx, query, page)
+			if err != nil {
+				yield(typing.ReleaseSummary{}, err)
+				return
+			}
+
+			for _, item := range result.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			// The server may cap the page size or leave out the total, only an empty page
+			// or reaching a known total ends the results
+			seen += len(result.Items)
+			if len(result.Items) == 0 || (result.TotalCount > 0 && seen >= result.TotalCount) {
+				return
+			}
+		}
+	}
+}
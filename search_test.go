@@ -0,0 +1,85 @@
+package crowdnfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+func TestClientSearch(t *testing.T) {
+	const total = 5
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/api/releases" || query.Get("search") != "Some.Show" || query.Get("category") != "TV" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		page, _ := strconv.Atoi(query.Get("page"))
+		pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+
+		result := typing.SearchPage{Page: page, PageSize: pageSize, TotalCount: total}
+		for i := (page - 1) * pageSize; i < total && i < page*pageSize; i++ {
+			result.Items = append(result.Items, typing.ReleaseSummary{ReleaseName: fmt.Sprintf("Some.Show.S01E%02d-GRP", i+1), Category: "TV"})
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL}
+
+	var names []string
+	for summary, err := range client.Search(context.Background(), SearchQuery{Query: "Some.Show", Category: "TV", PageSize: 2}) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		names = append(names, summary.ReleaseName)
+	}
+	if len(names) != total || names[0] != "Some.Show.S01E01-GRP" || names[total-1] != "Some.Show.S01E05-GRP" {
+		t.Errorf("Unexpected results: %v", names)
+	}
+
+	for _, err := range client.Search(context.Background(), SearchQuery{Query: "Some.Show", Category: "Anime"}) {
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("Expected ErrValidation for invalid category, got %v", err)
+		}
+	}
+}
+
+func TestClientSearchCappedPagesWithoutTotal(t *testing.T) {
+	const total, maxPageSize = 5, 2
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		// The page size is capped and the total count left out
+		var result typing.SearchPage
+		for i := (page - 1) * maxPageSize; i < total && i < page*maxPageSize; i++ {
+			result.Items = append(result.Items, typing.ReleaseSummary{ReleaseName: fmt.Sprintf("Some.Show.S01E%02d-GRP", i+1)})
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL}
+
+	var names []string
+	for summary, err := range client.Search(context.Background(), SearchQuery{Query: "Some.Show"}) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		names = append(names, summary.ReleaseName)
+	}
+	if len(names) != total {
+		t.Errorf("Expected all %d results, got %v", total, names)
+	}
+	if requests != 4 {
+		t.Errorf("Expected paging to stop at the first empty page, got %d requests", requests)
+	}
+}
@@ -0,0 +1,9 @@
+package typing
+
+import "context"
+
+// CredentialProvider supplies the CrowdNFO API key.
+// Implementations must be safe for concurrent use.
+type CredentialProvider interface {
+	APIKey(ctx context.Context) (string, error)
+}
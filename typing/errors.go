@@ -0,0 +1,92 @@
+package typing
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors describing the kind of an API failure, usable with errors.Is.
+var (
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrRateLimited   = errors.New("rate limited")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrValidation    = errors.New("validation failed")
+	ErrNotFound      = errors.New("not found")
+
+	// ErrUnsupportedVersion is returned once the server declared this client version unsupported
+	ErrUnsupportedVersion = errors.New("client version no longer supported by CrowdNFO, please update crowdnfo-go")
+)
+
+// APIError is returned when the CrowdNFO API answers with an unexpected status code.
+type APIError struct {
+	StatusCode  int
+	Operation   string // "upload", "download" or "query"
+	AssetType   string // MediaInfo, NFO or FileList, empty for other requests
+	ReleaseName string
+	Message     string // message sent by the server, if any
+}
+
+func (e *APIError) Error() string {
+	operation := e.Operation
+	if operation == "" {
+		operation = "request"
+	}
+	if e.AssetType != "" {
+		operation = e.AssetType + " " + operation
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("%s failed with status %d", operation, e.StatusCode)
+	}
+	return fmt.Sprintf("%s failed with status %d: %s", operation, e.StatusCode, e.Message)
+}
+
+// Is maps the status code onto the sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrAlreadyExists:
+		return e.StatusCode == http.StatusConflict
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// ReleaseNameProblem is a single violation of the scene naming rules
+type ReleaseNameProblem struct {
+	Pos     int // byte offset in the name, -1 if the problem concerns the whole name
+	Message string
+}
+
+func (p ReleaseNameProblem) String() string {
+	if p.Pos < 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("%s at position %d", p.Message, p.Pos)
+}
+
+// ReleaseNameError is returned for release names violating the scene naming rules.
+// It matches ErrValidation.
+type ReleaseNameError struct {
+	ReleaseName string
+	Problems    []ReleaseNameProblem
+}
+
+func (e *ReleaseNameError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.String()
+	}
+	return fmt.Sprintf("invalid release name %q: %s", e.ReleaseName, strings.Join(problems, "; "))
+}
+
+func (e *ReleaseNameError) Is(target error) bool {
+	return target == ErrValidation
+}
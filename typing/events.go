@@ -0,0 +1,46 @@
+package typing
+
+import "time"
+
+// Stage identifies the step an Event belongs to
+type Stage string
+
+const (
+	StageStartup   Stage = "startup"   // release detection, existing asset checks
+	StageMetadata  Stage = "metadata"  // MediaInfo, NFO and episode detection
+	StageHashing   Stage = "hashing"   // SHA-256 of the media file
+	StageUpload    Stage = "upload"    // uploads of the generated assets
+	StageRateLimit Stage = "ratelimit" // waiting for the client side rate limit
+)
+
+// Event reports the progress of ProcessRelease and ProcessReleases.
+type Event struct {
+	Stage        Stage
+	ReleaseName  string        // release or season pack being processed
+	EpisodeName  string        // release name of the episode, empty for single releases
+	EpisodeIndex int           // 1-based position of the episode in the season pack, 0 for single releases
+	EpisodeTotal int           // number of episodes in the season pack, 0 for single releases
+	AssetType    string        // MediaInfo, NFO or FileList for upload events
+	BytesDone    int64         // bytes processed so far, e.g. while hashing
+	BytesTotal   int64         // total bytes, 0 if unknown
+	Throughput   float64       // bytes per second while hashing
+	ETA          time.Duration // estimated time left while hashing
+	Elapsed      time.Duration // time since processing started
+	Detail       string        // human readable description
+	Err          error         // set if the step failed
+}
+
+// EventCB receives progress events
+type EventCB func(event Event)
+
+// ProgressEvents adapts a ProgressCB to receive events. The callback gets the stage,
+// the episode release name for season pack episodes or else the release name, and the detail.
+func ProgressEvents(cb ProgressCB) EventCB {
+	return func(event Event) {
+		releaseName := event.ReleaseName
+		if event.EpisodeName != "" {
+			releaseName = event.EpisodeName
+		}
+		cb(string(event.Stage), releaseName, event.Detail)
+	}
+}
@@ -0,0 +1,32 @@
+package typing
+
+import "context"
+
+// PayloadAction is the decision of a PayloadFilter
+type PayloadAction int
+
+const (
+	PayloadKeep   PayloadAction = iota // upload the payload unchanged
+	PayloadModify                      // upload the Payload of the decision instead
+	PayloadSkip                        // do not upload the asset, it is reported as AssetFiltered
+)
+
+// Payload is a MediaInfo, NFO or file list about to be uploaded
+type Payload struct {
+	ReleaseName      string
+	AssetType        string    // MediaInfo, NFO or FileList
+	OriginalFileName string    // NFO file name, empty otherwise
+	Data             []byte    // MediaInfo JSON or NFO content, nil for file lists
+	FileList         *FileList // nil for MediaInfo and NFO
+}
+
+// PayloadDecision tells what to do with a payload. The reason ends up in AssetResult.Reason.
+type PayloadDecision struct {
+	Action  PayloadAction
+	Payload Payload // replacement for PayloadModify, unset fields keep their value, release name and asset type cannot be changed
+	Reason  string
+}
+
+// PayloadFilter inspects every asset before it is uploaded, handed to an Uploader or written
+// by a dry run. It must be safe for concurrent use.
+type PayloadFilter func(ctx context.Context, payload Payload) PayloadDecision
@@ -0,0 +1,60 @@
+package typing
+
+import "time"
+
+// Release is a release as known to CrowdNFO
+type Release struct {
+	ReleaseName string        `json:"releaseName"`
+	Category    string        `json:"category"`
+	Files       []ReleaseFile `json:"files"`
+	HasFileList bool          `json:"hasFileList"`
+}
+
+// ReleaseFile describes an uploaded MediaInfo or NFO file of a release
+type ReleaseFile struct {
+	ID               string `json:"id"`
+	FileType         string `json:"fileType"`
+	OriginalFileName string `json:"originalFileName,omitempty"`
+	FileHash         string `json:"fileHash,omitempty"`
+}
+
+// File returns the first file of the given type, or nil if the release has none
+func (r *Release) File(fileType string) *ReleaseFile {
+	for i := range r.Files {
+		if r.Files[i].FileType == fileType {
+			return &r.Files[i]
+		}
+	}
+	return nil
+}
+
+// FileListEntry represents a single file in a file list
+type FileListEntry struct {
+	FilePath      string `json:"filePath"`
+	FileSizeBytes int64  `json:"fileSizeBytes"`
+}
+
+// FileList is the file list of a release
+type FileList struct {
+	ReleaseName string          `json:"releaseName"`
+	Category    string          `json:"category"`
+	Entries     []FileListEntry `json:"entries"`
+}
+
+// ReleaseSummary is a release as returned by the search
+type ReleaseSummary struct {
+	ReleaseName  string    `json:"releaseName"`
+	Category     string    `json:"category"`
+	HasMediaInfo bool      `json:"hasMediaInfo"`
+	HasNFO       bool      `json:"hasNfo"`
+	HasFileList  bool      `json:"hasFileList"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// SearchPage is a single page of search results
+type SearchPage struct {
+	Items      []ReleaseSummary `json:"items"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"pageSize"`
+	TotalCount int              `json:"totalCount"`
+}
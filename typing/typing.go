@@ -1,8 +1,120 @@
 package typing
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // ProcessResult holds the result of processing a release, including any non-fatal warnings.
 type ProcessResult struct {
 	Warnings []error
+	Assets   []AssetResult
+	Notice   *ServerNotice   // set if the server announced an update or deprecation
+	DryRun   []DryRunPayload // payloads that would have been sent, only in dry run mode
+}
+
+// ReleaseResult holds the assets of a single release or season pack episode
+type ReleaseResult struct {
+	ReleaseName string        `json:"releaseName"`
+	Assets      []AssetResult `json:"assets"`
+}
+
+// Releases groups the assets by release in the order the releases were processed.
+// Season packs get one entry per episode.
+func (r ProcessResult) Releases() []ReleaseResult {
+	var releases []ReleaseResult
+	index := make(map[string]int)
+	for _, asset := range r.Assets {
+		i, ok := index[asset.ReleaseName]
+		if !ok {
+			i = len(releases)
+			index[asset.ReleaseName] = i
+			releases = append(releases, ReleaseResult{ReleaseName: asset.ReleaseName})
+		}
+		releases[i].Assets = append(releases[i].Assets, asset)
+	}
+	return releases
 }
 
+// MarshalJSON encodes the result with the assets grouped by release and the warnings as strings
+func (r ProcessResult) MarshalJSON() ([]byte, error) {
+	warnings := make([]string, 0, len(r.Warnings))
+	for _, warning := range r.Warnings {
+		warnings = append(warnings, warning.Error())
+	}
+	return json.Marshal(struct {
+		Warnings []string        `json:"warnings"`
+		Releases []ReleaseResult `json:"releases"`
+		Notice   *ServerNotice   `json:"notice,omitempty"`
+		DryRun   []DryRunPayload `json:"dryRun,omitempty"`
+	}{warnings, r.Releases(), r.Notice, r.DryRun})
+}
+
+// DryRunPayload describes a request that would have been sent to CrowdNFO.
+type DryRunPayload struct {
+	ReleaseName string            `json:"releaseName"`
+	AssetType   string            `json:"assetType"`
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	Fields      map[string]string `json:"fields,omitempty"`   // multipart form fields
+	FileName    string            `json:"fileName,omitempty"` // name of the multipart file part
+	Body        []byte            `json:"-"`                  // file content or JSON body
+	Path        string            `json:"path,omitempty"`     // file the body was written to, if any
+}
+
+// Upload status of an asset
+const (
+	AssetUploaded = "uploaded"
+	AssetSkipped  = "skipped" // already present on CrowdNFO
+	AssetFailed   = "failed"
+	AssetQueued   = "queued"   // failed temporarily, spooled to disk for FlushQueue
+	AssetDryRun   = "dry-run"  // payload built but not sent
+	AssetFiltered = "filtered" // vetoed by a PayloadFilter
+)
+
+// AssetResult describes the upload of a single asset (MediaInfo, NFO or FileList) of a release.
+// Season packs get one AssetResult per asset and episode.
+type AssetResult struct {
+	ReleaseName string        `json:"releaseName"`
+	AssetType   string        `json:"assetType"`
+	Status      string        `json:"status"`               // AssetUploaded, AssetSkipped, AssetFailed, AssetQueued, AssetDryRun or AssetFiltered
+	Attempts    int           `json:"attempts,omitempty"`   // number of HTTP requests made, including retries
+	StatusCode  int           `json:"statusCode,omitempty"` // HTTP status of the last attempt
+	ID          string        `json:"id,omitempty"`         // ID assigned by CrowdNFO, if returned
+	URL         string        `json:"url,omitempty"`        // URL of the asset on CrowdNFO, if returned
+	BytesSent   int64         `json:"bytesSent,omitempty"`  // request body size of a single attempt
+	Duration    time.Duration `json:"duration,omitempty"`   // time spent on the upload including retries, in nanoseconds in JSON
+	Error       string        `json:"error,omitempty"`      // reason of a failed or queued upload
+	Reason      string        `json:"reason,omitempty"`     // reason given by a PayloadFilter that modified or vetoed the asset
+}
+
+// Retries returns the number of attempts after the first
+func (a AssetResult) Retries() int {
+	return max(a.Attempts-1, 0)
+}
+
+// ProgressCB receives progress as loose strings, see EventCB for structured events
 type ProgressCB func(stage string, releasename string, detail string)
+
+// RetryPolicy controls how failed CrowdNFO requests are retried.
+// Zero values are replaced by defaults, set MaxAttempts to 1 to disable retries.
+type RetryPolicy struct {
+	MaxAttempts    int           // total attempts per request, defaults to 3
+	InitialBackoff time.Duration // delay before the first retry, defaults to 1s
+	MaxBackoff     time.Duration // upper bound for the exponential backoff, defaults to 30s
+}
+
+// ServerNotice describes update and deprecation information announced by CrowdNFO in response headers.
+type ServerNotice struct {
+	ClientVersion   string `json:"clientVersion"`           // version of this library
+	MinVersion      string `json:"minVersion,omitempty"`    // oldest client version the server still accepts
+	LatestVersion   string `json:"latestVersion,omitempty"` // newest client version available
+	UpdateAvailable bool   `json:"updateAvailable"`         // LatestVersion is newer than ClientVersion
+	Unsupported     bool   `json:"unsupported"`             // ClientVersion is older than MinVersion, requests are refused
+	Deprecation     string `json:"deprecation,omitempty"`   // value of the Deprecation header of a deprecated endpoint
+	Sunset          string `json:"sunset,omitempty"`        // value of the Sunset header, when the endpoint goes away
+	Message         string `json:"message,omitempty"`       // free text notice sent by the server
+}
+
+// NoticeCB is called whenever the server notice changes
+type NoticeCB func(notice ServerNotice)
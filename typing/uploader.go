@@ -0,0 +1,26 @@
+package typing
+
+import (
+	"context"
+	"io"
+)
+
+// Uploader receives the metadata generated for a release.
+// The default implementation uploads to CrowdNFO, other implementations can
+// store the metadata elsewhere. Implementations must be safe for concurrent use.
+type Uploader interface {
+	UploadMediaInfo(ctx context.Context, file FileUpload) error
+	UploadNFO(ctx context.Context, file FileUpload) error
+	UploadFileList(ctx context.Context, fileList FileList) error
+}
+
+// FileUpload is a MediaInfo or NFO file handed to an Uploader
+type FileUpload struct {
+	ReleaseName      string
+	Category         string
+	Hash             string // SHA-256 of the media file, empty if not hashed
+	FileName         string // "<release name>.json" for MediaInfo, the NFO file name for NFOs
+	OriginalFileName string // NFO file name, empty for MediaInfo
+	Size             int64
+	Open             func() (io.ReadCloser, error) // returns the content, may be called repeatedly
+}
@@ -0,0 +1,150 @@
+package crowdnfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/crowdnfo/crowdnfo-go/internal/api"
+	"github.com/crowdnfo/crowdnfo-go/typing"
+)
+
+// UploadMediaInfo uploads a MediaInfo file to CrowdNFO using the client's API key.
+// Together with UploadNFO and UploadFileList it makes the client a typing.Uploader,
+// e.g. to combine it with other uploaders via MultiUploader.
+func (c *Client) UploadMediaInfo(ctx context.Context, file typing.FileUpload) error {
+	return c.call(ctx, func(apiKey string) error {
+		return c.apiClient().UploadFile(ctx, apiKey, api.MediaInfoType, file)
+	})
+}
+
+// UploadNFO uploads an NFO file to CrowdNFO using the client's API key
+func (c *Client) UploadNFO(ctx context.Context, file typing.FileUpload) error {
+	return c.call(ctx, func(apiKey string) error {
+		return c.apiClient().UploadFile(ctx, apiKey, api.NFOType, file)
+	})
+}
+
+// UploadFileList uploads a file list to CrowdNFO using the client's API key
+func (c *Client) UploadFileList(ctx context.Context, fileList typing.FileList) error {
+	return c.call(ctx, func(apiKey string) error {
+		return c.apiClient().UploadFileList(ctx, apiKey, fileList)
+	})
+}
+
+// DirUploader stores the metadata of each release in its own directory below Dir:
+// the MediaInfo as "<release name>.json", the NFO under its original name and the
+// file list as "FileList.json".
+type DirUploader struct {
+	Dir string
+}
+
+// NewDirUploader returns an uploader writing to dir
+func NewDirUploader(dir string) *DirUploader {
+	return &DirUploader{Dir: dir}
+}
+
+func (d *DirUploader) UploadMediaInfo(ctx context.Context, file typing.FileUpload) error {
+	return d.writeFile(file)
+}
+
+func (d *DirUploader) UploadNFO(ctx context.Context, file typing.FileUpload) error {
+	return d.writeFile(file)
+}
+
+func (d *DirUploader) UploadFileList(ctx context.Context, fileList typing.FileList) error {
+	data, err := json.MarshalIndent(fileList, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal file list: %w", err)
+	}
+	dir, err := d.releaseDir(fileList.ReleaseName)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, api.FileListType+".json"), data, 0644)
+}
+
+func (d *DirUploader) writeFile(file typing.FileUpload) error {
+	dir, err := d.releaseDir(file.ReleaseName)
+	if err != nil {
+		return err
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	fileName, err := baseName(file.FileName)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(filepath.Join(dir, fileName))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, content); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// releaseDir creates the directory of a release
+func (d *DirUploader) releaseDir(releaseName string) (string, error) {
+	name, err := baseName(releaseName)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(d.Dir, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// baseName reduces a name to its last path element, refusing names that would leave the directory
+func baseName(name string) (string, error) {
+	base := filepath.Base(name)
+	if base == "." || base == ".." || base == string(filepath.Separator) {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	return base, nil
+}
+
+// MultiUploader returns an uploader passing every upload to all given uploaders in order.
+// A failing uploader does not stop the others, their errors are joined.
+func MultiUploader(uploaders ...typing.Uploader) typing.Uploader {
+	return multiUploader(append([]typing.Uploader(nil), uploaders...))
+}
+
+type multiUploader []typing.Uploader
+
+func (m multiUploader) UploadMediaInfo(ctx context.Context, file typing.FileUpload) error {
+	var errs []error
+	for _, u := range m {
+		errs = append(errs, u.UploadMediaInfo(ctx, file))
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiUploader) UploadNFO(ctx context.Context, file typing.FileUpload) error {
+	var errs []error
+	for _, u := range m {
+		errs = append(errs, u.UploadNFO(ctx, file))
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiUploader) UploadFileList(ctx context.Context, fileList typing.FileList) error {
+	var errs []error
+	for _, u := range m {
+		errs = append(errs, u.UploadFileList(ctx, fileList))
+	}
+	return errors.Join(errs...)
+}